@@ -0,0 +1,128 @@
+// Command headless runs the particle demo without a window for a fixed
+// number of ticks and prints world statistics, for CI and profiling.
+//
+// Input comes from a recording made with the demo's -record flag, or from a
+// built-in script that sweeps the mouse around the screen and clicks. A
+// recording also sets the screen size and DPI, tick by tick, and the number
+// of ticks to run.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+
+	"ecs/game"
+	"ecs/lib"
+	"ecs/lib/replay"
+	"ecs/systems"
+)
+
+var (
+	ticks      = flag.Int("ticks", 600, "number of simulation ticks to run")
+	seed       = flag.Int64("seed", 1, "random seed for scripted runs")
+	width      = flag.Int("width", 800, "screen width in pixels")
+	height     = flag.Int("height", 450, "screen height in pixels")
+	dpi        = flag.Float64("dpi", 1, "screen DPI scale")
+	clickEvery = flag.Int("click-every", 60, "scripted input clicks every this many ticks")
+	replayPath = flag.String("replay", "", "replay input from this recording instead of the script")
+	jsonOutput = flag.Bool("json", false, "print stats as JSON")
+)
+
+// script sweeps the mouse in a circle around the screen centre.
+type script struct {
+	tick       int
+	width      float64
+	height     float64
+	clickEvery int
+}
+
+func (s *script) Poll() replay.Input {
+	angle := float64(s.tick) * 0.02
+	input := replay.Input{
+		MouseX: s.width/2 + math.Cos(angle)*s.width/3,
+		MouseY: s.height/2 + math.Sin(angle)*s.height/3,
+	}
+	if s.tick%s.clickEvery == 0 {
+		input.Pressed, input.Down = replay.ButtonLeft, replay.ButtonLeft
+	}
+	s.tick++
+	return input
+}
+
+func main() {
+	flag.Parse()
+	if *ticks < 0 {
+		usageError("-ticks must not be negative, got %d", *ticks)
+	}
+	if *clickEvery <= 0 {
+		usageError("-click-every must be positive, got %d", *clickEvery)
+	}
+
+	step := 1.0 / 60
+	source := replay.Source(&script{width: float64(*width), height: float64(*height), clickEvery: *clickEvery})
+	screen := systems.Screen(systems.FixedScreen{Width: int32(*width), Height: int32(*height), DPI: float32(*dpi)})
+	var player *replay.Player
+	if *replayPath != "" {
+		recording, err := replay.LoadFile(*replayPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		step, *seed = recording.Step, recording.Seed
+		player = replay.NewPlayer(recording)
+		source, screen = player, player
+	}
+
+	world := lib.NewWorld()
+	input := replay.NewInputSystem(source)
+	world.AddSystem(input)
+	game.Setup(world, game.Platform{
+		Input:  input,
+		Clock:  systems.FixedClock{Step: step},
+		Screen: screen,
+	}, rand.New(rand.NewSource(*seed)))
+
+	loop := lib.NewLoop(world, step)
+	if player != nil {
+		if err := replay.Replay(loop, player); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		for loop.Ticks() < uint64(*ticks) {
+			loop.Advance(step)
+		}
+	}
+
+	stats := world.Stats()
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(struct {
+			Ticks uint64         `json:"ticks"`
+			Hash  string         `json:"hash"`
+			Stats lib.WorldStats `json:"stats"`
+		}{loop.Ticks(), fmt.Sprintf("%016x", world.Hash()), stats}); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	fmt.Printf("ticks:       %d\n", loop.Ticks())
+	fmt.Printf("hash:        %016x\n", world.Hash())
+	fmt.Printf("entities:    %d\n", stats.Entities)
+	fmt.Printf("archetypes:  %d\n", stats.ArchetypeCount)
+	fmt.Printf("column size: %d bytes\n", stats.ColumnBytes)
+	fmt.Printf("query cache: %.1f%% hits\n", stats.QueryCacheHitRate*100)
+	for _, system := range stats.Systems {
+		fmt.Printf("system %-28s %v\n", system.Name, system.Duration)
+	}
+}
+
+func usageError(format string, args ...interface{}) {
+	fmt.Fprintf(flag.CommandLine.Output(), format+"\n", args...)
+	flag.Usage()
+	os.Exit(2)
+}
@@ -0,0 +1,58 @@
+// Package components defines the particle demo's component types.
+package components
+
+import "ecs/lib"
+
+type DebounceUpdateComponent struct {
+	DebounceTime float32
+	CurrentTime  float32
+}
+
+type TextComponent struct {
+	Text     string
+	FontSize int32
+}
+
+type PositionComponent struct {
+	X, Y int32
+}
+
+type ColorComponent struct {
+	R, G, B, A uint8
+}
+
+type ParticleSpawnComponent struct {
+	LifeTime float32
+}
+type ParticleComponent struct {
+	Radius float32
+}
+type LifetimeComponent struct {
+	LifeTime    float32
+	CurrentTime float32
+}
+
+type FPSComponent struct{}
+type FrameTimeComponent struct{}
+type ShouldUpdateComponent struct{}
+type EntityCounterComponent struct{}
+type VisibleComponent struct{}
+type SpeedComponent struct {
+	Speed float32
+}
+
+// Labels show the FPS and frame time, which follow the wall clock, so they are
+// left out of World.Hash for replays to match the recorded session.
+var TextComponentID = lib.RegisterComponent[TextComponent](lib.Unhashed())
+var PositionComponentID = lib.RegisterComponent[PositionComponent]()
+var FPSComponentID = lib.RegisterComponent[FPSComponent]()
+var FrameTimeComponentID = lib.RegisterComponent[FrameTimeComponent]()
+var DebounceUpdateComponentID = lib.RegisterComponent[DebounceUpdateComponent]()
+var ShouldUpdateComponentID = lib.RegisterComponent[ShouldUpdateComponent](lib.WithStorage(lib.SparseSetStorage))
+var ColorComponentID = lib.RegisterComponent[ColorComponent]()
+var ParticleSpawnComponentID = lib.RegisterComponent[ParticleSpawnComponent]()
+var ParticleComponentID = lib.RegisterComponent[ParticleComponent]()
+var LifetimeComponentID = lib.RegisterComponent[LifetimeComponent]()
+var EntityCounterComponentID = lib.RegisterComponent[EntityCounterComponent]()
+var VisibleComponentID = lib.RegisterComponent[VisibleComponent]()
+var SpeedComponentID = lib.RegisterComponent[SpeedComponent]()
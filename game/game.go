@@ -0,0 +1,69 @@
+// Package game assembles the particle demo from the components and systems
+// packages, free of any windowing or graphics dependency so it can run
+// headless.
+package game
+
+import (
+	"math/rand"
+
+	"ecs/components"
+	"ecs/lib"
+	"ecs/systems"
+)
+
+// Platform is everything the game reads from outside the World.
+type Platform struct {
+	Input  systems.Input
+	Clock  systems.Clock
+	Screen systems.Screen
+}
+
+// Setup spawns the HUD and the particle spawner into world and adds the demo
+// systems in update order. Randomness comes from rng only, so a seeded game
+// replays identically.
+func Setup(world *lib.World, platform Platform, rng *rand.Rand) {
+	scaled := func(value int32) int32 { return systems.ToScaled(platform.Screen, value) }
+
+	fpsCounter := world.CreateEntity()
+	world.AddComponents(fpsCounter,
+		components.TextComponent{Text: "FPS: %d", FontSize: scaled(20)},
+		components.PositionComponent{X: scaled(10), Y: scaled(10)},
+		components.FPSComponent{},
+		components.DebounceUpdateComponent{DebounceTime: 0.1, CurrentTime: 0},
+		components.ShouldUpdateComponent{},
+		components.VisibleComponent{},
+	)
+	frameTime := world.CreateEntity()
+	world.AddComponents(frameTime,
+		components.TextComponent{Text: "Frame time: %d", FontSize: scaled(14)},
+		components.PositionComponent{X: scaled(10), Y: scaled(30)},
+		components.FrameTimeComponent{},
+		components.DebounceUpdateComponent{DebounceTime: 1, CurrentTime: 0},
+		components.ShouldUpdateComponent{},
+		components.VisibleComponent{},
+	)
+
+	particleSpawn := world.CreateEntity()
+	world.AddComponents(particleSpawn,
+		components.ParticleSpawnComponent{LifeTime: 20},
+		components.PositionComponent{X: 0, Y: 0},
+		components.ColorComponent{R: 1, G: 0, B: 0, A: 1},
+	)
+
+	entitiesCounter := world.CreateEntity()
+	world.AddComponents(entitiesCounter,
+		components.TextComponent{Text: "Entities: %d", FontSize: scaled(14)},
+		components.PositionComponent{X: scaled(10), Y: scaled(50)},
+		components.EntityCounterComponent{},
+		components.ShouldUpdateComponent{},
+		components.DebounceUpdateComponent{DebounceTime: 0.1, CurrentTime: 0},
+		components.VisibleComponent{},
+	)
+
+	world.AddSystem(systems.NewDebounceSystem())
+	world.AddSystem(systems.NewLifetimeSystem())
+	world.AddSystem(systems.NewColorFadeSystem(systems.DefaultColorFadeConfig))
+	world.AddSystem(systems.NewSpawnerSystem(systems.DefaultSpawnerConfig, platform.Input, platform.Screen, rng))
+	world.AddSystem(systems.NewHUDSystem(platform.Clock, platform.Input))
+	world.AddSystem(systems.NewAttractionSystem(systems.DefaultAttractionConfig, platform.Input, platform.Screen))
+}
@@ -0,0 +1,105 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"ecs/components"
+	"ecs/lib"
+	"ecs/lib/replay"
+	"ecs/systems"
+)
+
+type fakeInput struct {
+	input replay.Input
+}
+
+func (i *fakeInput) Current() replay.Input {
+	return i.input
+}
+
+func newTestGame(input systems.Input, seed int64) *lib.World {
+	world := lib.NewWorld()
+	Setup(world, Platform{
+		Input:  input,
+		Clock:  systems.FixedClock{Step: 1.0 / 60},
+		Screen: systems.FixedScreen{Width: 800, Height: 450, DPI: 1},
+	}, rand.New(rand.NewSource(seed)))
+	return world
+}
+
+func TestGame_ClickSpawnsParticlesAwayFromMouse(t *testing.T) {
+	input := &fakeInput{input: replay.Input{MouseX: 400, MouseY: 200, Pressed: replay.ButtonLeft}}
+	world := newTestGame(input, 1)
+	world.Update(1.0 / 60)
+
+	particles := 0
+	for _, position := range lib.Values[components.PositionComponent](world.Query().With(components.ParticleComponentID)) {
+		particles++
+		if math.Hypot(float64(position.X-400), float64(position.Y-200)) < 190 {
+			t.Errorf("particle spawned too close to the mouse at %+v", position)
+		}
+	}
+	if particles == 0 || particles > 300 {
+		t.Errorf("expected up to 300 particles, got %d", particles)
+	}
+
+	input.input.Pressed = 0
+	world.Update(1.0 / 60)
+	if got := len(world.Query().With(components.ParticleComponentID).Get().Entities); got != particles {
+		t.Errorf("expected no spawning without a click, got %d particles", got)
+	}
+}
+
+func TestGame_ParticlesExpire(t *testing.T) {
+	input := &fakeInput{input: replay.Input{Pressed: replay.ButtonLeft}}
+	world := newTestGame(input, 1)
+	world.Update(1.0 / 60)
+	input.input.Pressed = 0
+
+	for i := 0; i < 20*60+1; i++ {
+		world.Update(1.0 / 60)
+	}
+	if got := len(world.Query().With(components.ParticleComponentID).Get().Entities); got != 0 {
+		t.Errorf("expected particles to expire after their lifetime, %d left", got)
+	}
+}
+
+func TestGame_Deterministic(t *testing.T) {
+	run := func() uint64 {
+		input := &fakeInput{}
+		world := newTestGame(input, 42)
+		for tick := 0; tick < 120; tick++ {
+			input.input = replay.Input{MouseX: float64(tick * 5), MouseY: 100}
+			if tick%30 == 0 {
+				input.input.Pressed = replay.ButtonLeft
+			}
+			world.Update(1.0 / 60)
+		}
+		return world.Hash()
+	}
+	if run() != run() {
+		t.Error("expected equal seeds and inputs to give equal worlds")
+	}
+}
+
+func TestGame_HashIgnoresClock(t *testing.T) {
+	run := func(clock systems.Clock) uint64 {
+		input := &fakeInput{input: replay.Input{MouseX: 400, MouseY: 200, Pressed: replay.ButtonLeft}}
+		world := lib.NewWorld()
+		Setup(world, Platform{
+			Input:  input,
+			Clock:  clock,
+			Screen: systems.FixedScreen{Width: 800, Height: 450, DPI: 1},
+		}, rand.New(rand.NewSource(42)))
+		for tick := 0; tick < 120; tick++ {
+			world.Update(1.0 / 60)
+			input.input.Pressed = 0
+		}
+		return world.Hash()
+	}
+	if run(systems.FixedClock{Step: 1.0 / 60}) != run(systems.FixedClock{Step: 1.0 / 25}) {
+		t.Error("expected the HUD's clock readings to stay out of the hash")
+	}
+}
@@ -2,9 +2,10 @@ module ecs
 
 go 1.23.4
 
+require github.com/gen2brain/raylib-go/raylib v0.0.0-20250109172833-6dbba4f81a9b
+
 require (
 	github.com/ebitengine/purego v0.8.2 // indirect
-	github.com/gen2brain/raylib-go/raylib v0.0.0-20250109172833-6dbba4f81a9b // indirect
 	golang.org/x/exp v0.0.0-20250106191152-7588d65b2ba8 // indirect
 	golang.org/x/sys v0.29.0 // indirect
 )
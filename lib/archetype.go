@@ -1,17 +1,104 @@
 package lib
 
 type Archetype struct {
-	bitset                Bitset
-	components            map[ComponentID][]interface{}
-	entities              []EntityID
-	disabledMaskPerEntity map[EntityID]Bitset
+	bitset     Bitset
+	components map[ComponentID]*pages[interface{}]
+	entities   pages[EntityID]
+	// disabled holds the disabled components of each row.
+	disabled pages[Bitset]
 }
 
 func NewArchetype(bitset Bitset, entityCapacity int, componentsCapacity int) *Archetype {
 	return &Archetype{
-		bitset:                bitset,
-		components:            make(map[ComponentID][]interface{}, componentsCapacity),
-		entities:              make([]EntityID, entityCapacity),
-		disabledMaskPerEntity: make(map[EntityID]Bitset, entityCapacity),
+		bitset:     bitset,
+		components: make(map[ComponentID]*pages[interface{}], componentsCapacity),
+		entities:   newPages[EntityID](entityCapacity),
+		disabled:   newPages[Bitset](entityCapacity),
 	}
 }
+
+func (a *Archetype) len() int {
+	return a.entities.len()
+}
+
+func (a *Archetype) entity(row int) EntityID {
+	return a.entities.at(row)
+}
+
+// appendRow adds a row for entity and returns its index. The caller fills in
+// every column with setValue.
+func (a *Archetype) appendRow(entity EntityID, disabled Bitset) int {
+	row := a.entities.len()
+	a.entities.append(entity)
+	a.disabled.append(disabled)
+	return row
+}
+
+// setValue writes a component into a row, appending it if the row is the new
+// last one and the column has not reached it yet.
+func (a *Archetype) setValue(componentID ComponentID, row int, component interface{}) {
+	if Registry.isTag(componentID) {
+		return
+	}
+	column, exists := a.components[componentID]
+	if !exists {
+		column = &pages[interface{}]{}
+		a.components[componentID] = column
+	}
+	if row < column.len() {
+		column.set(row, component)
+		return
+	}
+	column.append(component)
+}
+
+// value reads a component of the row; tags have no column and always read as
+// their zero value.
+func (a *Archetype) value(componentID ComponentID, row int) interface{} {
+	if column, exists := a.components[componentID]; exists {
+		return column.at(row)
+	}
+	if a.bitset.HasID(componentID) {
+		return Registry.tags[componentID]
+	}
+	return nil
+}
+
+// removeRow swaps the last row into the removed one and returns the entity
+// that moved, if any, as its row index changed. Only the pages holding the
+// two rows are written.
+func (a *Archetype) removeRow(row int) (EntityID, bool) {
+	lastIdx := a.entities.len() - 1
+	var moved EntityID
+	swapped := row != lastIdx
+	if swapped {
+		moved = a.entities.at(lastIdx)
+		a.entities.set(row, moved)
+		a.disabled.set(row, a.disabled.at(lastIdx))
+		for _, column := range a.components {
+			column.set(row, column.at(lastIdx))
+		}
+	}
+	a.entities.pop()
+	a.disabled.pop()
+	for _, column := range a.components {
+		column.pop()
+	}
+	return moved, swapped
+}
+
+// share hands out a frozen copy of the archetype for a Snapshot. Both sides
+// keep pointing at the same pages until the live archetype writes to them.
+func (a *Archetype) share() *Archetype {
+	frozen := &Archetype{
+		bitset:     a.bitset,
+		components: make(map[ComponentID]*pages[interface{}], len(a.components)),
+		entities:   a.entities.share(),
+		disabled:   a.disabled.share(),
+	}
+	for componentID, column := range a.components {
+		shared := column.share()
+		frozen.components[componentID] = &shared
+	}
+	return frozen
+}
@@ -0,0 +1,64 @@
+package lib
+
+import "reflect"
+
+// Get returns the entity's component of type T. Disabled components are
+// reported as missing, matching what queries see.
+func Get[T any](w *World, entity EntityID) (T, bool) {
+	var zero T
+	componentID, exists := Registry.typeToID[reflect.TypeOf(zero)]
+	if !exists {
+		return zero, false
+	}
+	component, exists := w.GetComponent(entity, componentID)
+	if !exists {
+		return zero, false
+	}
+	return component.(T), true
+}
+
+func Has[T any](w *World, entity EntityID) bool {
+	var zero T
+	componentID, exists := Registry.typeToID[reflect.TypeOf(zero)]
+	return exists && w.HasComponent(entity, componentID)
+}
+
+func (w *World) GetComponent(entity EntityID, componentID ComponentID) (interface{}, bool) {
+	component, exists := w.componentValue(entity, componentID)
+	if !exists || w.isDisabled(entity, componentID) {
+		return nil, false
+	}
+	return component, true
+}
+
+func (w *World) HasComponent(entity EntityID, componentID ComponentID) bool {
+	_, exists := w.GetComponent(entity, componentID)
+	return exists
+}
+
+// ComponentsOf lists every component attached to the entity, enabled or not,
+// in ascending id order.
+func (w *World) ComponentsOf(entity EntityID) []ComponentID {
+	if _, row := w.entityRow(entity); row == -1 {
+		return nil
+	}
+	return w.componentMask(entity).IDs()
+}
+
+// componentMask covers both table and sparse components of an entity.
+func (w *World) componentMask(entity EntityID) Bitset {
+	archetype, row := w.entityRow(entity)
+	if row == -1 {
+		return 0
+	}
+	present, _ := w.sparseMask(entity)
+	return archetype.bitset | present
+}
+
+func (w *World) entityRow(entity EntityID) (*Archetype, int) {
+	location, exists := w.location(entity)
+	if !exists || location.row == -1 {
+		return nil, -1
+	}
+	return w.archetypes[location.archetype], location.row
+}
@@ -0,0 +1,84 @@
+package lib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	w := NewWorld()
+	entity := w.CreateEntity()
+	other := w.CreateEntity()
+	w.AddComponents(entity, CharacterComponent{name: "hero"}, PositionComponent{x: 1, y: 2})
+	w.AddComponents(other, CharacterComponent{name: "villain"})
+	w.DestroyEntity(w.CreateEntity())
+
+	if character, ok := Get[CharacterComponent](w, entity); !ok || character.name != "hero" {
+		t.Errorf("expected hero, got %v (%v)", character, ok)
+	}
+	if character, ok := Get[CharacterComponent](w, other); !ok || character.name != "villain" {
+		t.Errorf("expected villain, got %v (%v)", character, ok)
+	}
+	if _, ok := Get[PositionComponent](w, other); ok {
+		t.Errorf("expected no position on %v", other)
+	}
+	if _, ok := Get[unregisteredComponent](w, entity); ok {
+		t.Errorf("expected unregistered component to be missing")
+	}
+	if _, ok := Get[CharacterComponent](w, 1000); ok {
+		t.Errorf("expected missing entity to have no components")
+	}
+
+	w.DisableComponent(entity, GetComponentID[PositionComponent]())
+	if _, ok := Get[PositionComponent](w, entity); ok {
+		t.Errorf("expected disabled position to be hidden")
+	}
+}
+
+func TestGet_AfterArchetypeChurn(t *testing.T) {
+	w := NewWorld()
+	entities := make([]EntityID, 10)
+	for i := range entities {
+		entities[i] = w.CreateEntity()
+		w.AddComponents(entities[i], PositionComponent{x: float64(i)})
+	}
+	w.AddComponents(entities[2], CharacterComponent{})
+	w.DestroyEntity(entities[0])
+	w.RemoveComponent(entities[5], GetComponentID[PositionComponent]())
+
+	for i, entity := range entities {
+		position, ok := Get[PositionComponent](w, entity)
+		expected := i != 0 && i != 5
+		if ok != expected || (ok && position.x != float64(i)) {
+			t.Errorf("entity %v: expected x=%d present=%v, got %v present=%v", entity, i, expected, position, ok)
+		}
+	}
+}
+
+func TestWorld_HasComponentAndComponentsOf(t *testing.T) {
+	w := NewWorld()
+	entity := w.CreateEntity()
+	w.AddComponents(entity, IsEnabledComponent{}, CharacterComponent{})
+
+	characterID := GetComponentID[CharacterComponent]()
+	enabledID := GetComponentID[IsEnabledComponent]()
+	if !w.HasComponent(entity, characterID) || !Has[IsEnabledComponent](w, entity) {
+		t.Errorf("expected entity to have both components")
+	}
+	if Has[PositionComponent](w, entity) {
+		t.Errorf("expected entity to have no position")
+	}
+
+	w.DisableComponent(entity, enabledID)
+	if Has[IsEnabledComponent](w, entity) {
+		t.Errorf("expected disabled component to be hidden")
+	}
+
+	expected := []ComponentID{characterID, enabledID}
+	if got := w.ComponentsOf(entity); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if got := w.ComponentsOf(w.CreateEntity()); len(got) != 0 {
+		t.Errorf("expected no components, got %v", got)
+	}
+}
@@ -8,25 +8,144 @@ import (
 type ComponentRegistry struct {
 	nextID   ComponentID
 	typeToID map[reflect.Type]ComponentID
+	idToType map[ComponentID]reflect.Type
+	info     map[ComponentID]*componentInfo
+
+	// names maps both the short type name and the full Type.String() to
+	// every component registered under it.
+	names map[string][]ComponentID
+
+	// Zero-sized component types are tags: they live only in archetype
+	// bitsets and read back as this shared zero value.
+	tags map[ComponentID]interface{}
+
+	// Components stored in sparse sets outside archetype identity.
+	sparse Bitset
+}
+
+type componentInfo struct {
+	defaultValue func() interface{}
+	onRemove     func(EntityID, interface{})
+	clone        func(interface{}) interface{}
+	remap        func(interface{}, func(EntityID) EntityID) interface{}
+	storage      StorageKind
+	replicated   bool
+	unhashed     bool
 }
 
+// ComponentOption configures a component type at registration time.
+type ComponentOption func(*componentInfo)
+
 var Registry = ComponentRegistry{
 	nextID:   1,
 	typeToID: make(map[reflect.Type]ComponentID),
+	idToType: make(map[ComponentID]reflect.Type),
+	info:     make(map[ComponentID]*componentInfo),
+	names:    make(map[string][]ComponentID),
+	tags:     make(map[ComponentID]interface{}),
+}
+
+// WithDefault sets the constructor used when a component is added by ID only.
+func WithDefault[T any](fn func() T) ComponentOption {
+	return func(info *componentInfo) {
+		info.defaultValue = func() interface{} { return fn() }
+	}
 }
 
-func RegisterComponent[T any]() ComponentID {
+// WithOnRemove sets a destructor called with the old value whenever the
+// component leaves an entity, including when the entity is destroyed and when
+// AddComponents overwrites it with a new value.
+func WithOnRemove[T any](fn func(EntityID, T)) ComponentOption {
+	return func(info *componentInfo) {
+		info.onRemove = func(entity EntityID, component interface{}) { fn(entity, component.(T)) }
+	}
+}
+
+// WithClone sets the deep-copy function used by World.CloneEntity.
+func WithClone[T any](fn func(T) T) ComponentOption {
+	return func(info *componentInfo) {
+		info.clone = func(component interface{}) interface{} { return fn(component.(T)) }
+	}
+}
+
+// WithEntityRefs lets entities stored inside a component follow their targets
+// when a group of entities is cloned or moved to another world.
+func WithEntityRefs[T any](fn func(T, func(EntityID) EntityID) T) ComponentOption {
+	return func(info *componentInfo) {
+		info.remap = func(component interface{}, remap func(EntityID) EntityID) interface{} {
+			return fn(component.(T), remap)
+		}
+	}
+}
+
+// WithStorage picks where a component's values live. It must be given the
+// first time the type is registered; registering the type again with a
+// different kind panics.
+func WithStorage(kind StorageKind) ComponentOption {
+	return func(info *componentInfo) {
+		info.storage = kind
+	}
+}
+
+// Replicated marks a component for network replication: its exported fields
+// are sent to clients as they change, keyed by the type's full name, so that
+// name must not be shared with any other registered component.
+func Replicated() ComponentOption {
+	return func(info *componentInfo) {
+		info.replicated = true
+	}
+}
+
+// Unhashed leaves a component out of World.Hash and Diff, for presentation
+// state such as an FPS label that follows the wall clock and so can never
+// match between a live session and its replay.
+func Unhashed() ComponentOption {
+	return func(info *componentInfo) {
+		info.unhashed = true
+	}
+}
+
+func RegisterComponent[T any](options ...ComponentOption) ComponentID {
 	var component T
 	componentType := reflect.TypeOf(component)
 
-	if id, exists := Registry.typeToID[componentType]; exists {
-		return id
+	id, exists := Registry.typeToID[componentType]
+	if !exists {
+		id = Registry.nextID
+		Registry.typeToID[componentType] = id
+		Registry.idToType[id] = componentType
+		Registry.info[id] = &componentInfo{}
+		Registry.addName(componentType.String(), id)
+		if name := componentType.Name(); name != componentType.String() {
+			Registry.addName(name, id)
+		}
+		if componentType.Size() == 0 {
+			Registry.tags[id] = component
+		}
+		Registry.nextID++
 	}
 
-	id := Registry.nextID
-	Registry.typeToID[componentType] = id
-	Registry.nextID++
-	fmt.Printf("registered component %v with id %v\n", componentType, id)
+	info := Registry.info[id]
+	storage := info.storage
+	for _, option := range options {
+		option(info)
+	}
+	if exists && info.storage != storage {
+		info.storage = storage
+		panic(fmt.Sprintf("component %v is already registered with another storage kind", componentType))
+	}
+	if info.storage == SparseSetStorage {
+		Registry.sparse = Registry.sparse.AddID(id)
+	} else {
+		Registry.sparse = Registry.sparse.RemoveID(id)
+	}
+	if sameName := Registry.names[componentType.String()]; len(sameName) > 1 {
+		for _, other := range sameName {
+			if Registry.IsReplicated(other) {
+				panic(fmt.Sprintf("replicated component %v shares its name with another component", componentType))
+			}
+		}
+	}
 	return id
 }
 
@@ -36,11 +155,97 @@ func GetComponentID[T any]() ComponentID {
 }
 
 func GetComponentIDOf[T any](component T) ComponentID {
+	id, err := TryGetComponentIDOf(component)
+	if err != nil {
+		panic(err.Error())
+	}
+	return id
+}
+
+// GetComponentIDByName finds a registered component by its type name, as shown
+// by the inspector, or by its full name such as "game.Position". A short name
+// shared by types from several packages is ErrComponentAmbiguous.
+func GetComponentIDByName(name string) (ComponentID, error) {
+	ids := Registry.names[name]
+	switch {
+	case len(ids) == 0:
+		return 0, fmt.Errorf("%w: %s", ErrComponentNotRegistered, name)
+	case len(ids) > 1:
+		return 0, fmt.Errorf("%w: %s", ErrComponentAmbiguous, name)
+	}
+	return ids[0], nil
+}
+
+func TryGetComponentIDOf[T any](component T) (ComponentID, error) {
 	componentType := reflect.TypeOf(component)
 
 	id, exists := Registry.typeToID[componentType]
 	if !exists {
-		panic(fmt.Sprintf("component type %v not registered", componentType))
+		return 0, fmt.Errorf("%w: component type %v", ErrComponentNotRegistered, componentType)
 	}
-	return id
+	return id, nil
+}
+
+func (r *ComponentRegistry) IsReplicated(id ComponentID) bool {
+	info := r.info[id]
+	return info != nil && info.replicated
+}
+
+// TypeOf returns the Go type registered under id.
+func (r *ComponentRegistry) TypeOf(id ComponentID) (reflect.Type, bool) {
+	componentType, exists := r.idToType[id]
+	return componentType, exists
+}
+
+func (r *ComponentRegistry) addName(name string, id ComponentID) {
+	if name != "" {
+		r.names[name] = append(r.names[name], id)
+	}
+}
+
+func (r *ComponentRegistry) isTag(id ComponentID) bool {
+	_, isTag := r.tags[id]
+	return isTag
+}
+
+func (r *ComponentRegistry) isUnhashed(id ComponentID) bool {
+	info := r.info[id]
+	return info != nil && info.unhashed
+}
+
+func (r *ComponentRegistry) isSparse(id ComponentID) bool {
+	return r.sparse.HasID(id)
+}
+
+func (r *ComponentRegistry) newComponent(id ComponentID) interface{} {
+	if info := r.info[id]; info != nil && info.defaultValue != nil {
+		return info.defaultValue()
+	}
+	return reflect.Zero(r.idToType[id]).Interface()
+}
+
+func (r *ComponentRegistry) cloneComponent(id ComponentID, component interface{}) interface{} {
+	if info := r.info[id]; info != nil && info.clone != nil {
+		return info.clone(component)
+	}
+	return component
+}
+
+func (r *ComponentRegistry) removeComponent(id ComponentID, entity EntityID, component interface{}) {
+	if info := r.info[id]; info != nil && info.onRemove != nil {
+		info.onRemove(entity, component)
+	}
+}
+
+func (r *ComponentRegistry) remapComponent(id ComponentID, component interface{}, entities map[EntityID]EntityID) interface{} {
+	info := r.info[id]
+	if info == nil || info.remap == nil {
+		return component
+	}
+	return info.remap(component, func(entity EntityID) EntityID {
+		if mapped, exists := entities[entity]; exists {
+			return mapped
+		}
+		return entity
+	})
 }
@@ -0,0 +1,166 @@
+// Package debugserver exposes a running World over HTTP for live inspection.
+//
+// Handlers never touch the World directly: each request is queued and runs
+// when the frame loop calls Drain, so the World stays single-threaded.
+package debugserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ecs/lib"
+)
+
+type Server struct {
+	world    *lib.World
+	requests chan func()
+	mux      *http.ServeMux
+}
+
+type response struct {
+	status      int
+	contentType string
+	body        []byte
+}
+
+func New(world *lib.World) *Server {
+	s := &Server{
+		world:    world,
+		requests: make(chan func(), 64),
+		mux:      http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /entities", s.handle(s.entities))
+	s.mux.HandleFunc("GET /entities/{id}", s.handle(s.entity))
+	s.mux.HandleFunc("POST /entities/{id}/components/{component}/{action}", s.handle(s.toggleComponent))
+	s.mux.HandleFunc("GET /archetypes", s.handle(s.archetypes))
+	s.mux.HandleFunc("GET /archetypes.dot", s.handle(s.archetypeGraph))
+	s.mux.HandleFunc("GET /stats", s.handle(s.stats))
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Drain runs the requests queued so far against the World. Call it from the
+// frame loop between updates. Requests arriving while it runs wait for the
+// next call, so a busy client can't hold up the frame.
+func (s *Server) Drain() {
+	for queued := len(s.requests); queued > 0; queued-- {
+		(<-s.requests)()
+	}
+}
+
+// handle queues fn for the frame loop and waits for its response, giving up
+// if the client goes away first.
+func (s *Server) handle(fn func(*http.Request) response) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		done := make(chan response, 1)
+		select {
+		case s.requests <- func() { done <- fn(r) }:
+		case <-r.Context().Done():
+			return
+		}
+
+		select {
+		case res := <-done:
+			w.Header().Set("Content-Type", res.contentType)
+			w.WriteHeader(res.status)
+			_, _ = w.Write(res.body)
+		case <-r.Context().Done():
+		}
+	}
+}
+
+func (s *Server) entities(*http.Request) response {
+	entities := make([]lib.EntitySnapshot, 0, s.world.GetEntityCount())
+	for _, archetype := range s.world.Archetypes() {
+		entities = append(entities, archetype.Entities...)
+	}
+	return jsonResponse(http.StatusOK, entities)
+}
+
+func (s *Server) entity(r *http.Request) response {
+	entity, err := parseEntity(r)
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	if !s.world.HasEntity(entity) {
+		return errorResponse(http.StatusNotFound, lib.ErrEntityNotFound)
+	}
+	return jsonResponse(http.StatusOK, s.world.InspectEntity(entity))
+}
+
+func (s *Server) toggleComponent(r *http.Request) response {
+	entity, err := parseEntity(r)
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	componentID, err := parseComponent(r)
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+
+	switch r.PathValue("action") {
+	case "enable":
+		err = s.world.TryEnableComponent(entity, componentID)
+	case "disable":
+		err = s.world.TryDisableComponent(entity, componentID)
+	default:
+		return errorResponse(http.StatusNotFound, errors.New("action must be enable or disable"))
+	}
+
+	switch {
+	case errors.Is(err, lib.ErrEntityNotFound), errors.Is(err, lib.ErrComponentMissing):
+		return errorResponse(http.StatusNotFound, err)
+	case err != nil:
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	return jsonResponse(http.StatusOK, s.world.InspectEntity(entity))
+}
+
+func (s *Server) archetypes(*http.Request) response {
+	return jsonResponse(http.StatusOK, s.world.Archetypes())
+}
+
+func (s *Server) archetypeGraph(*http.Request) response {
+	var body bytes.Buffer
+	if err := s.world.Inspect(&body, lib.InspectDOT); err != nil {
+		return errorResponse(http.StatusInternalServerError, err)
+	}
+	return response{status: http.StatusOK, contentType: "text/vnd.graphviz", body: body.Bytes()}
+}
+
+func (s *Server) stats(*http.Request) response {
+	return jsonResponse(http.StatusOK, s.world.Stats())
+}
+
+func parseEntity(r *http.Request) (lib.EntityID, error) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	return lib.EntityID(id), err
+}
+
+// parseComponent accepts a component id or its type name.
+func parseComponent(r *http.Request) (lib.ComponentID, error) {
+	value := r.PathValue("component")
+	if id, err := strconv.ParseUint(value, 10, 64); err == nil {
+		return lib.ComponentID(id), nil
+	}
+	return lib.GetComponentIDByName(value)
+}
+
+func jsonResponse(status int, value interface{}) response {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return errorResponse(http.StatusInternalServerError, err)
+	}
+	return response{status: status, contentType: "application/json", body: body}
+}
+
+func errorResponse(status int, err error) response {
+	body, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return response{status: status, contentType: "application/json", body: body}
+}
@@ -0,0 +1,167 @@
+package debugserver
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"ecs/lib"
+)
+
+type Position struct {
+	X, Y int
+}
+
+type Visible struct{}
+
+type Velocity struct {
+	DX, DY int
+}
+
+var positionID = lib.RegisterComponent[Position]()
+var visibleID = lib.RegisterComponent[Visible]()
+var _ = lib.RegisterComponent[Velocity]()
+
+// startFrameLoop drains the server the way a game loop would until the
+// returned stop function is called.
+func startFrameLoop(t *testing.T, world *lib.World) (*httptest.Server, func()) {
+	server := New(world)
+	httpServer := httptest.NewServer(server)
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				world.Update(0.001)
+				server.Drain()
+			}
+		}
+	}()
+	return httpServer, func() {
+		httpServer.Close()
+		close(stop)
+		<-stopped
+	}
+}
+
+// request may run on another goroutine, so it reports failures with t.Error
+// rather than t.Fatal.
+func request(t *testing.T, method, url string) (int, string) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Error(err)
+		return 0, ""
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Error(err)
+		return 0, ""
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	return res.StatusCode, string(body)
+}
+
+func TestServer(t *testing.T) {
+	world := lib.NewWorld()
+	entity := world.CreateEntity()
+	world.AddComponents(entity, Position{X: 3, Y: 4}, Visible{})
+	server, stop := startFrameLoop(t, world)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		status   int
+		contains string
+	}{
+		{"list entities", "GET", "/entities", 200, `"name":"Position","fields":[{"name":"X","value":3}`},
+		{"get entity", "GET", "/entities/0", 200, `"id":0`},
+		{"missing entity", "GET", "/entities/42", 404, "entity not found"},
+		{"invalid entity", "GET", "/entities/abc", 400, "invalid syntax"},
+		{"archetypes", "GET", "/archetypes", 200, `"components":["Position","Visible"]`},
+		{"archetype graph", "GET", "/archetypes.dot", 200, "digraph archetypes"},
+		{"stats", "GET", "/stats", 200, `"entities":1`},
+		{"disable by name", "POST", "/entities/0/components/Visible/disable", 200, `"name":"Visible","disabled":true`},
+		{"enable by id", "POST", "/entities/0/components/" + strconv.Itoa(int(visibleID)) + "/enable", 200, `"name":"Visible"}`},
+		{"unknown component", "POST", "/entities/0/components/Nope/disable", 400, "component not registered"},
+		{"component on missing entity", "POST", "/entities/42/components/Visible/disable", 404, "entity not found"},
+		{"missing component", "POST", "/entities/0/components/Velocity/disable", 404, "component missing"},
+		{"unknown action", "POST", "/entities/0/components/Visible/toggle", 404, "action must be"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := request(t, tt.method, server.URL+tt.path)
+			if status != tt.status || !strings.Contains(body, tt.contains) {
+				t.Errorf("expected %d containing %q, got %d: %s", tt.status, tt.contains, status, body)
+			}
+		})
+	}
+
+	request(t, "POST", server.URL+"/entities/0/components/Position/disable")
+	stop()
+	if lib.Has[Position](world, entity) {
+		t.Errorf("expected the queued request to have disabled Position")
+	}
+}
+
+func TestServer_RequestsWaitForDrain(t *testing.T) {
+	world := lib.NewWorld()
+	server := New(world)
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	done := make(chan int)
+	go func() {
+		status, _ := request(t, "GET", httpServer.URL+"/stats")
+		done <- status
+	}()
+
+	select {
+	case <-done:
+		t.Fatalf("expected the request to wait for Drain")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	for {
+		server.Drain()
+		select {
+		case status := <-done:
+			if status != http.StatusOK {
+				t.Errorf("expected 200, got %d", status)
+			}
+			return
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestServer_DrainRunsOnlyQueuedRequests(t *testing.T) {
+	server := New(lib.NewWorld())
+	ran := 0
+	var requeue func()
+	requeue = func() {
+		ran++
+		server.requests <- requeue
+	}
+	server.requests <- requeue
+	server.requests <- requeue
+
+	server.Drain()
+	if ran != 2 {
+		t.Errorf("expected the 2 queued requests to run, got %d", ran)
+	}
+	if len(server.requests) != 2 {
+		t.Errorf("expected requests queued during Drain to wait, got %d queued", len(server.requests))
+	}
+}
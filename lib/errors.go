@@ -0,0 +1,65 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Every World mutation has a TryXxx variant returning one of these errors.
+// The plain variants ignore missing entities and components, leaving the
+// world untouched, but still panic on unregistered component types.
+var (
+	ErrEntityNotFound         = errors.New("entity not found")
+	ErrComponentNotRegistered = errors.New("component not registered")
+	ErrComponentMissing       = errors.New("component missing")
+	ErrComponentAmbiguous     = errors.New("component name is ambiguous")
+	ErrDuplicateEntity        = errors.New("entity listed more than once")
+	ErrFieldNotIndexable      = errors.New("field cannot be indexed")
+)
+
+func ignoreMissing(err error) {
+	if errors.Is(err, ErrComponentNotRegistered) {
+		panic(err.Error())
+	}
+}
+
+func checkRegistered(componentID ComponentID) error {
+	if _, exists := Registry.idToType[componentID]; !exists {
+		return fmt.Errorf("%w: component id %d", ErrComponentNotRegistered, componentID)
+	}
+	return nil
+}
+
+func (w *World) checkEntity(entity EntityID) error {
+	if _, exists := w.location(entity); !exists {
+		return fmt.Errorf("%w: entity %d", ErrEntityNotFound, entity)
+	}
+	return nil
+}
+
+func (w *World) checkEntities(entities []EntityID) error {
+	seen := make(map[EntityID]struct{}, len(entities))
+	for _, entity := range entities {
+		if err := w.checkEntity(entity); err != nil {
+			return err
+		}
+		if _, duplicate := seen[entity]; duplicate {
+			return fmt.Errorf("%w: entity %d", ErrDuplicateEntity, entity)
+		}
+		seen[entity] = struct{}{}
+	}
+	return nil
+}
+
+func (w *World) checkComponent(entity EntityID, componentID ComponentID) error {
+	if err := w.checkEntity(entity); err != nil {
+		return err
+	}
+	if err := checkRegistered(componentID); err != nil {
+		return err
+	}
+	if _, exists := w.componentValue(entity, componentID); !exists {
+		return fmt.Errorf("%w: entity %d has no component %d", ErrComponentMissing, entity, componentID)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type unregisteredComponent struct{}
+
+// Duration shares its short name with time.Duration.
+type Duration int
+
+func TestWorld_TryErrors(t *testing.T) {
+	positionID := GetComponentID[PositionComponent]()
+	characterID := GetComponentID[CharacterComponent]()
+	unregisteredID := ComponentID(63)
+
+	tests := []struct {
+		name     string
+		mutate   func(w *World, entity EntityID) error
+		expected error
+	}{
+		{"destroy missing entity", func(w *World, _ EntityID) error {
+			return w.TryDestroyEntity(1000)
+		}, ErrEntityNotFound},
+		{"destroy entity twice", func(w *World, e EntityID) error {
+			w.DestroyEntity(e)
+			return w.TryDestroyEntity(e)
+		}, ErrEntityNotFound},
+		{"add to missing entity", func(w *World, _ EntityID) error {
+			return w.TryAddComponents(1000, PositionComponent{})
+		}, ErrEntityNotFound},
+		{"add unregistered component", func(w *World, e EntityID) error {
+			return w.TryAddComponents(e, PositionComponent{}, unregisteredComponent{})
+		}, ErrComponentNotRegistered},
+		{"add default unregistered component", func(w *World, e EntityID) error {
+			return w.TryAddDefaultComponents(e, unregisteredID)
+		}, ErrComponentNotRegistered},
+		{"remove missing component", func(w *World, e EntityID) error {
+			return w.TryRemoveComponent(e, positionID)
+		}, ErrComponentMissing},
+		{"remove from missing entity", func(w *World, _ EntityID) error {
+			return w.TryRemoveComponent(1000, characterID)
+		}, ErrEntityNotFound},
+		{"remove unregistered component", func(w *World, e EntityID) error {
+			return w.TryRemoveComponent(e, unregisteredID)
+		}, ErrComponentNotRegistered},
+		{"disable on entity without archetype", func(w *World, _ EntityID) error {
+			return w.TryDisableComponent(w.CreateEntity(), characterID)
+		}, ErrComponentMissing},
+		{"disable missing component", func(w *World, e EntityID) error {
+			return w.TryDisableComponent(e, positionID)
+		}, ErrComponentMissing},
+		{"enable on missing entity", func(w *World, _ EntityID) error {
+			return w.TryEnableComponent(1000, characterID)
+		}, ErrEntityNotFound},
+		{"clone missing entity", func(w *World, e EntityID) error {
+			_, err := w.TryCloneEntities(e, 1000)
+			return err
+		}, ErrEntityNotFound},
+		{"move missing entity", func(w *World, _ EntityID) error {
+			_, err := w.TryMoveEntityTo(NewWorld(), 1000)
+			return err
+		}, ErrEntityNotFound},
+		{"valid mutation", func(w *World, e EntityID) error {
+			return w.TryRemoveComponent(e, characterID)
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWorld()
+			entity := w.CreateEntity()
+			w.AddComponents(entity, CharacterComponent{name: "test"})
+
+			err := tt.mutate(w, entity)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestWorld_FailedMutationLeavesEntityInPlace(t *testing.T) {
+	w := NewWorld()
+	entity := w.CreateEntity()
+	w.AddComponents(entity, CharacterComponent{name: "test"})
+	bitset := w.locations.at(int(entity)).archetype
+
+	w.RemoveComponent(entity, GetComponentID[PositionComponent]())
+	if err := w.TryAddComponents(entity, PositionComponent{}, unregisteredComponent{}); err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	if w.locations.at(int(entity)).archetype != bitset {
+		t.Errorf("expected entity to stay in archetype %b, got %b", bitset, w.locations.at(int(entity)).archetype)
+	}
+}
+
+func TestTryGetComponentIDOf(t *testing.T) {
+	if _, err := TryGetComponentIDOf(unregisteredComponent{}); !errors.Is(err, ErrComponentNotRegistered) {
+		t.Errorf("expected %v, got %v", ErrComponentNotRegistered, err)
+	}
+	id, err := TryGetComponentIDOf(PositionComponent{})
+	if err != nil || id != GetComponentID[PositionComponent]() {
+		t.Errorf("expected id %d, got %d (%v)", GetComponentID[PositionComponent](), id, err)
+	}
+}
+
+func TestGetComponentIDByName(t *testing.T) {
+	localID := RegisterComponent[Duration]()
+	timeID := RegisterComponent[time.Duration]()
+
+	if id, err := GetComponentIDByName("lib.Duration"); err != nil || id != localID {
+		t.Errorf("expected id %d for the full name, got %d (%v)", localID, id, err)
+	}
+	if id, err := GetComponentIDByName("time.Duration"); err != nil || id != timeID {
+		t.Errorf("expected id %d for the full name, got %d (%v)", timeID, id, err)
+	}
+	if _, err := GetComponentIDByName("Duration"); !errors.Is(err, ErrComponentAmbiguous) {
+		t.Errorf("expected %v, got %v", ErrComponentAmbiguous, err)
+	}
+	if id, err := GetComponentIDByName("PositionComponent"); err != nil || id != GetComponentID[PositionComponent]() {
+		t.Errorf("expected the short name to resolve, got %d (%v)", id, err)
+	}
+	if _, err := GetComponentIDByName("Nope"); !errors.Is(err, ErrComponentNotRegistered) {
+		t.Errorf("expected %v, got %v", ErrComponentNotRegistered, err)
+	}
+}
+
+func TestRegisterComponent_ReplicatedNameClash(t *testing.T) {
+	type twin struct{ A int }
+	RegisterComponent[twin](Replicated())
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a replicated component sharing its full name")
+		}
+	}()
+	func() {
+		type twin struct{ B int }
+		RegisterComponent[twin]()
+	}()
+}
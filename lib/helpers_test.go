@@ -0,0 +1,12 @@
+package lib
+
+// spawn creates count entities in w, giving entity i the components returned
+// by components(i), and returns them in creation order.
+func spawn(w *World, count int, components func(i int) []interface{}) []EntityID {
+	entities := make([]EntityID, count)
+	for i := range entities {
+		entities[i] = w.CreateEntity()
+		w.AddComponents(entities[i], components(i)...)
+	}
+	return entities
+}
@@ -1,5 +1,7 @@
 package lib
 
+import "math/bits"
+
 type Bitset uint64
 
 type ID uint64
@@ -8,6 +10,15 @@ type ComponentID ID
 
 type EntityID ID
 
+// IDs lists the component ids in the set in ascending order.
+func (b Bitset) IDs() []ComponentID {
+	ids := make([]ComponentID, 0, bits.OnesCount64(uint64(b)))
+	for rest := uint64(b); rest != 0; rest &= rest - 1 {
+		ids = append(ids, ComponentID(bits.TrailingZeros64(rest)))
+	}
+	return ids
+}
+
 func (b Bitset) HasID(id ComponentID) bool {
 	return b&(1<<id) != 0
 }
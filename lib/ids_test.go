@@ -1,6 +1,7 @@
 package lib
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -108,3 +109,24 @@ func TestBitset_Without(t *testing.T) {
 		})
 	}
 }
+
+func TestBitset_IDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		bitset   Bitset
+		expected []ComponentID
+	}{
+		{"empty set", Bitset(0), []ComponentID{}},
+		{"single id", Bitset(0b100), []ComponentID{2}},
+		{"ascending ids", Bitset(0b1011), []ComponentID{0, 1, 3}},
+		{"highest id", Bitset(1 << 63), []ComponentID{63}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.bitset.IDs()
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package lib
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+type IndexKind int
+
+const (
+	HashIndex IndexKind = iota
+	SortedIndex
+)
+
+// componentIndex is kept in sync with one component type's values by the
+// World as entities gain, update and lose that component.
+type componentIndex interface {
+	set(entity EntityID, component interface{})
+	unset(entity EntityID)
+}
+
+type fieldIndex struct {
+	field      string
+	fieldIndex []int
+	kind       IndexKind
+	keys       map[EntityID]interface{}
+	hash       map[interface{}]map[EntityID]struct{}
+	sorted     []indexEntry
+}
+
+type indexEntry struct {
+	key    interface{}
+	entity EntityID
+}
+
+// CreateIndex declares an index on a field of component T and fills it from
+// the entities already in the world. Sorted indexes also answer LookupRange.
+// It panics on fields TryCreateIndex rejects.
+func CreateIndex[T any](w *World, field string, kind IndexKind) {
+	if err := TryCreateIndex[T](w, field, kind); err != nil {
+		panic(err.Error())
+	}
+}
+
+// TryCreateIndex is CreateIndex returning ErrFieldNotIndexable for a field
+// whose values could not be used as keys: one that doesn't exist, holds an
+// interface, slice, map or func anywhere in its type, or is an unexported
+// field of a type other than a number, string or bool.
+func TryCreateIndex[T any](w *World, field string, kind IndexKind) error {
+	componentID := GetComponentID[T]()
+	if w.fieldIndex(componentID, field) != nil {
+		return nil
+	}
+	componentType := Registry.idToType[componentID]
+	structField, exists := componentType.FieldByName(field)
+	if !exists {
+		return fmt.Errorf("%w: component type %v has no field %s", ErrFieldNotIndexable, componentType, field)
+	}
+	if !isKeyType(structField.Type) {
+		return fmt.Errorf("%w: field %v.%s of type %v", ErrFieldNotIndexable, componentType, field, structField.Type)
+	}
+	if !structField.IsExported() && !isScalarKind(structField.Type.Kind()) {
+		return fmt.Errorf("%w: unexported field %v.%s of kind %v", ErrFieldNotIndexable, componentType, field, structField.Type.Kind())
+	}
+	if kind == SortedIndex && !isOrderedKind(structField.Type.Kind()) {
+		return fmt.Errorf("%w: field %v.%s of kind %v cannot be sorted", ErrFieldNotIndexable, componentType, field, structField.Type.Kind())
+	}
+
+	index := &fieldIndex{
+		field:      field,
+		fieldIndex: structField.Index,
+		kind:       kind,
+		keys:       make(map[EntityID]interface{}),
+		hash:       make(map[interface{}]map[EntityID]struct{}),
+	}
+	w.eachComponent(componentID, index.set)
+	w.indexes[componentID] = append(w.indexes[componentID], index)
+	return nil
+}
+
+// Lookup returns the entities whose T component has field equal to key, in
+// ascending id order, including entities where T is disabled. Without an
+// index on the field it falls back to a scan.
+func Lookup[T any](w *World, field string, key interface{}) []EntityID {
+	componentID := GetComponentID[T]()
+	_, key = fieldKeyOf(Registry.idToType[componentID], field, key)
+	if index := w.fieldIndex(componentID, field); index != nil {
+		return index.lookup(key)
+	}
+	return w.scanField(componentID, field, func(value interface{}) bool { return value == key })
+}
+
+// LookupRange returns the entities whose T component has field in [from, to],
+// ordered by field value then id.
+func LookupRange[T any](w *World, field string, from, to interface{}) []EntityID {
+	componentID := GetComponentID[T]()
+	componentType := Registry.idToType[componentID]
+	_, from = fieldKeyOf(componentType, field, from)
+	_, to = fieldKeyOf(componentType, field, to)
+	if index := w.fieldIndex(componentID, field); index != nil && index.kind == SortedIndex {
+		return index.lookupRange(from, to)
+	}
+
+	index := &fieldIndex{field: field, kind: SortedIndex, keys: make(map[EntityID]interface{})}
+	structField, _ := componentType.FieldByName(field)
+	index.fieldIndex = structField.Index
+	for _, entity := range w.scanField(componentID, field, func(value interface{}) bool {
+		return compareKeys(value, from) >= 0 && compareKeys(value, to) <= 0
+	}) {
+		component, _ := w.componentValue(entity, componentID)
+		index.set(entity, component)
+	}
+	return index.lookupRange(from, to)
+}
+
+func (w *World) fieldIndex(componentID ComponentID, field string) *fieldIndex {
+	for _, index := range w.indexes[componentID] {
+		if index, ok := index.(*fieldIndex); ok && index.field == field {
+			return index
+		}
+	}
+	return nil
+}
+
+func (w *World) scanField(componentID ComponentID, field string, match func(interface{}) bool) []EntityID {
+	structField, _ := Registry.idToType[componentID].FieldByName(field)
+	entities := make([]EntityID, 0)
+	w.eachComponent(componentID, func(entity EntityID, component interface{}) {
+		if match(fieldValue(component, structField.Index)) {
+			entities = append(entities, entity)
+		}
+	})
+	sort.Slice(entities, func(i, j int) bool { return entities[i] < entities[j] })
+	return entities
+}
+
+func (w *World) setIndexed(entity EntityID, componentID ComponentID, component interface{}) {
+	for _, index := range w.indexes[componentID] {
+		index.set(entity, component)
+	}
+}
+
+func (w *World) unsetIndexed(entity EntityID, componentID ComponentID) {
+	for _, index := range w.indexes[componentID] {
+		index.unset(entity)
+	}
+}
+
+func (i *fieldIndex) set(entity EntityID, component interface{}) {
+	key := fieldValue(component, i.fieldIndex)
+	if i.kind == HashIndex {
+		key = hashKey(key)
+	}
+	if old, exists := i.keys[entity]; exists {
+		if old == key {
+			return
+		}
+		i.unset(entity)
+	}
+	i.keys[entity] = key
+
+	if i.kind == SortedIndex {
+		at := i.search(key, entity)
+		i.sorted = append(i.sorted, indexEntry{})
+		copy(i.sorted[at+1:], i.sorted[at:])
+		i.sorted[at] = indexEntry{key: key, entity: entity}
+		return
+	}
+	entities, exists := i.hash[key]
+	if !exists {
+		entities = make(map[EntityID]struct{})
+		i.hash[key] = entities
+	}
+	entities[entity] = struct{}{}
+}
+
+func (i *fieldIndex) unset(entity EntityID) {
+	key, exists := i.keys[entity]
+	if !exists {
+		return
+	}
+	delete(i.keys, entity)
+
+	if i.kind == SortedIndex {
+		at := i.search(key, entity)
+		i.sorted = append(i.sorted[:at], i.sorted[at+1:]...)
+		return
+	}
+	delete(i.hash[key], entity)
+	if len(i.hash[key]) == 0 {
+		delete(i.hash, key)
+	}
+}
+
+func (i *fieldIndex) lookup(key interface{}) []EntityID {
+	if i.kind == SortedIndex {
+		return i.lookupRange(key, key)
+	}
+	entities := make([]EntityID, 0, len(i.hash[key]))
+	for entity := range i.hash[key] {
+		entities = append(entities, entity)
+	}
+	sort.Slice(entities, func(a, b int) bool { return entities[a] < entities[b] })
+	return entities
+}
+
+func (i *fieldIndex) lookupRange(from, to interface{}) []EntityID {
+	start := sort.Search(len(i.sorted), func(at int) bool { return compareKeys(i.sorted[at].key, from) >= 0 })
+	entities := make([]EntityID, 0)
+	for at := start; at < len(i.sorted) && compareKeys(i.sorted[at].key, to) <= 0; at++ {
+		entities = append(entities, i.sorted[at].entity)
+	}
+	return entities
+}
+
+// search finds the position of (key, entity) in the sorted entries.
+func (i *fieldIndex) search(key interface{}, entity EntityID) int {
+	return sort.Search(len(i.sorted), func(at int) bool {
+		if c := compareKeys(i.sorted[at].key, key); c != 0 {
+			return c > 0
+		}
+		return i.sorted[at].entity >= entity
+	})
+}
+
+// nanKey stands in for NaN in hash indexes: NaN never equals itself, so an
+// entry keyed by it could not be found again to remove. Lookup still passes
+// NaN through unchanged, so like a scan it matches nothing.
+type nanKey struct{}
+
+func hashKey(key interface{}) interface{} {
+	if f, ok := key.(float64); ok && math.IsNaN(f) {
+		return nanKey{}
+	}
+	return key
+}
+
+// isKeyType reports whether every value of t can be a map key. Interfaces
+// are comparable as a type but panic when they hold a slice or map.
+func isKeyType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface, reflect.Slice, reflect.Map, reflect.Func:
+		return false
+	case reflect.Array:
+		return isKeyType(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !isKeyType(t.Field(i).Type) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isScalarKind reports whether canonical can read the kind from an unexported
+// field.
+func isScalarKind(kind reflect.Kind) bool {
+	return kind == reflect.Bool || isOrderedKind(kind)
+}
+
+func isOrderedKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+	return false
+}
+
+// compareKeys orders keys produced by canonical for the same field.
+func compareKeys(a, b interface{}) int {
+	switch a := a.(type) {
+	case int64:
+		return cmp.Compare(a, b.(int64))
+	case uint64:
+		return cmp.Compare(a, b.(uint64))
+	case float64:
+		return cmp.Compare(a, b.(float64))
+	case string:
+		return cmp.Compare(a, b.(string))
+	}
+	panic(fmt.Sprintf("keys of type %T cannot be ordered", a))
+}
@@ -0,0 +1,211 @@
+package lib
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	for _, kind := range []IndexKind{HashIndex, SortedIndex} {
+		w := NewWorld()
+		hero := w.CreateEntity()
+		villain := w.CreateEntity()
+		w.AddComponents(hero, CharacterComponent{name: "hero"})
+		w.AddComponents(villain, CharacterComponent{name: "villain"})
+
+		CreateIndex[CharacterComponent](w, "name", kind)
+		sidekick := w.CreateEntity()
+		w.AddComponents(sidekick, CharacterComponent{name: "hero"})
+
+		if got := Lookup[CharacterComponent](w, "name", "hero"); !reflect.DeepEqual(got, []EntityID{hero, sidekick}) {
+			t.Errorf("kind %v: expected heroes %v, got %v", kind, []EntityID{hero, sidekick}, got)
+		}
+
+		w.AddComponents(hero, CharacterComponent{name: "retired"})
+		w.RemoveComponent(villain, GetComponentID[CharacterComponent]())
+		if got := Lookup[CharacterComponent](w, "name", "hero"); !reflect.DeepEqual(got, []EntityID{sidekick}) {
+			t.Errorf("kind %v: expected %v after update, got %v", kind, []EntityID{sidekick}, got)
+		}
+		if got := Lookup[CharacterComponent](w, "name", "villain"); len(got) != 0 {
+			t.Errorf("kind %v: expected no villains after removal, got %v", kind, got)
+		}
+	}
+}
+
+func TestLookupRange(t *testing.T) {
+	w := NewWorld()
+	for i := 0; i < 10; i++ {
+		w.AddComponents(w.CreateEntity(), PositionComponent{x: float64(9 - i)})
+	}
+	expected := []EntityID{7, 6, 5}
+
+	if got := LookupRange[PositionComponent](w, "x", 2, 4); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected scan %v, got %v", expected, got)
+	}
+	CreateIndex[PositionComponent](w, "x", SortedIndex)
+	if got := LookupRange[PositionComponent](w, "x", 2, 4); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected indexed %v, got %v", expected, got)
+	}
+}
+
+func TestIndex_ConsistentUnderChurn(t *testing.T) {
+	w := NewWorld()
+	CreateIndex[layerComponent](w, "Layer", HashIndex)
+	CreateIndex[PositionComponent](w, "x", SortedIndex)
+	random := rand.New(rand.NewSource(1))
+	other := NewWorld()
+
+	alive := make([]EntityID, 0)
+	for step := 0; step < 5000; step++ {
+		switch op := random.Intn(6); {
+		case op == 0 || len(alive) == 0:
+			entity := w.CreateEntity()
+			w.AddComponents(entity, layerComponent{Layer: int32(random.Intn(4))}, PositionComponent{x: float64(random.Intn(20))})
+			alive = append(alive, entity)
+		case op == 1:
+			i := random.Intn(len(alive))
+			w.DestroyEntity(alive[i])
+			alive = append(alive[:i], alive[i+1:]...)
+		case op == 2:
+			w.RemoveComponent(alive[random.Intn(len(alive))], GetComponentID[layerComponent]())
+		case op == 3:
+			w.AddComponents(alive[random.Intn(len(alive))], CharacterComponent{})
+		case op == 4:
+			i := random.Intn(len(alive))
+			w.MoveEntityTo(other, alive[i])
+			alive = append(alive[:i], alive[i+1:]...)
+		default:
+			w.AddComponents(alive[random.Intn(len(alive))], layerComponent{Layer: int32(random.Intn(4))}, PositionComponent{x: float64(random.Intn(20))})
+		}
+	}
+
+	for layer := 0; layer < 4; layer++ {
+		indexed := Lookup[layerComponent](w, "Layer", layer)
+		scanned := w.scanField(GetComponentID[layerComponent](), "Layer", func(v interface{}) bool { return v == int64(layer) })
+		if !reflect.DeepEqual(indexed, scanned) {
+			t.Errorf("layer %d: index %v disagrees with scan %v", layer, indexed, scanned)
+		}
+	}
+	indexed := LookupRange[PositionComponent](w, "x", 5, 12)
+	scanned := w.scanField(GetComponentID[PositionComponent](), "x", func(v interface{}) bool {
+		return v.(float64) >= 5 && v.(float64) <= 12
+	})
+	sort.Slice(indexed, func(i, j int) bool { return indexed[i] < indexed[j] })
+	if !reflect.DeepEqual(indexed, scanned) {
+		t.Errorf("range: index %v disagrees with scan %v", indexed, scanned)
+	}
+}
+
+func TestIndex_FloatKeysUnderChurn(t *testing.T) {
+	w := NewWorld()
+	CreateIndex[PositionComponent](w, "x", HashIndex)
+	keys := []float64{0, 1.5, math.Inf(1), math.NaN()}
+	random := rand.New(rand.NewSource(1))
+
+	alive := make([]EntityID, 0)
+	for step := 0; step < 2000; step++ {
+		switch op := random.Intn(3); {
+		case op == 0 || len(alive) == 0:
+			entity := w.CreateEntity()
+			w.AddComponents(entity, PositionComponent{x: keys[random.Intn(len(keys))]})
+			alive = append(alive, entity)
+		case op == 1:
+			i := random.Intn(len(alive))
+			w.DestroyEntity(alive[i])
+			alive = append(alive[:i], alive[i+1:]...)
+		default:
+			w.AddComponents(alive[random.Intn(len(alive))], PositionComponent{x: keys[random.Intn(len(keys))]})
+		}
+	}
+
+	index := w.fieldIndex(GetComponentID[PositionComponent](), "x")
+	entries := 0
+	for _, entities := range index.hash {
+		entries += len(entities)
+	}
+	if entries != len(alive) || len(index.keys) != len(alive) {
+		t.Errorf("expected %d entries for %d live entities, got %d keyed and %d hashed", len(alive), len(alive), len(index.keys), entries)
+	}
+	if len(index.hash) > len(keys) {
+		t.Errorf("expected at most %d distinct keys, got %d", len(keys), len(index.hash))
+	}
+	if got := Lookup[PositionComponent](w, "x", math.NaN()); len(got) != 0 {
+		t.Errorf("expected NaN to match nothing, got %v", got)
+	}
+	for _, key := range keys[:3] {
+		indexed := Lookup[PositionComponent](w, "x", key)
+		scanned := w.scanField(GetComponentID[PositionComponent](), "x", func(v interface{}) bool { return v == key })
+		if !reflect.DeepEqual(indexed, scanned) {
+			t.Errorf("key %v: index %v disagrees with scan %v", key, indexed, scanned)
+		}
+	}
+}
+
+type anchorComponent struct {
+	Tag    interface{}
+	origin struct{ x, y int }
+	Spot   struct{ Cells []int }
+	layer  int
+}
+
+var _ = RegisterComponent[anchorComponent]()
+
+func TestTryCreateIndex_RejectsUnindexableFields(t *testing.T) {
+	w := NewWorld()
+	for _, field := range []string{"Tag", "origin", "Spot", "missing"} {
+		if err := TryCreateIndex[anchorComponent](w, field, HashIndex); !errors.Is(err, ErrFieldNotIndexable) {
+			t.Errorf("field %s: expected %v, got %v", field, ErrFieldNotIndexable, err)
+		}
+	}
+	if err := TryCreateIndex[pathComponent](w, "points", HashIndex); !errors.Is(err, ErrFieldNotIndexable) {
+		t.Errorf("expected %v for a slice field, got %v", ErrFieldNotIndexable, err)
+	}
+	if err := TryCreateIndex[anchorComponent](w, "layer", HashIndex); err != nil {
+		t.Fatalf("expected an unexported int field to be indexable, got %v", err)
+	}
+
+	entity := w.CreateEntity()
+	w.AddComponents(entity, anchorComponent{Tag: []int{1}, layer: 2})
+	if got := Lookup[anchorComponent](w, "layer", 2); !reflect.DeepEqual(got, []EntityID{entity}) {
+		t.Errorf("expected %v, got %v", []EntityID{entity}, got)
+	}
+}
+
+func TestWhereEq_UsesIndex(t *testing.T) {
+	w := NewWorld()
+	spawn(w, 12, func(i int) []interface{} {
+		return []interface{}{layerComponent{Layer: int32(i % 4)}, PositionComponent{x: float64(i)}}
+	})
+	w.DisableComponent(6, GetComponentID[PositionComponent]())
+
+	query := func() *Query {
+		return WhereEq[layerComponent](w.Query().With(GetComponentID[PositionComponent]()), "Layer", 2)
+	}
+	collect := func() ([]EntityID, []EntityID) {
+		fromAll := make([]EntityID, 0)
+		for entity := range query().Entities() {
+			fromAll = append(fromAll, entity)
+		}
+		fromEach := make([]EntityID, 0)
+		query().Each(func(id EntityID, _ map[ComponentID]interface{}) { fromEach = append(fromEach, id) })
+		return fromAll, fromEach
+	}
+
+	scannedAll, scannedEach := collect()
+	CreateIndex[layerComponent](w, "Layer", HashIndex)
+	if _, indexed := query().indexedEntities(); !indexed {
+		t.Fatalf("expected the query to use the index")
+	}
+	indexedAll, indexedEach := collect()
+
+	expected := []EntityID{2, 10}
+	for _, got := range [][]EntityID{scannedAll, scannedEach, indexedAll, indexedEach} {
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected %v, got %v", expected, got)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+type InspectFormat int
+
+const (
+	InspectText InspectFormat = iota
+	InspectJSON
+	// InspectDOT renders the archetype graph for Graphviz: one node per
+	// archetype and an edge for every single-component transition.
+	InspectDOT
+)
+
+type ArchetypeSnapshot struct {
+	Bitset     Bitset           `json:"bitset"`
+	Components []string         `json:"components"`
+	Entities   []EntitySnapshot `json:"entities"`
+}
+
+type EntitySnapshot struct {
+	ID         EntityID            `json:"id"`
+	Components []ComponentSnapshot `json:"components"`
+	References []EntityReference   `json:"references,omitempty"`
+}
+
+type ComponentSnapshot struct {
+	ID       ComponentID  `json:"id"`
+	Name     string       `json:"name"`
+	Disabled bool         `json:"disabled,omitempty"`
+	Sparse   bool         `json:"sparse,omitempty"`
+	Fields   []FieldValue `json:"fields,omitempty"`
+}
+
+type FieldValue struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON writes the value as text when JSON can't hold it, such as a NaN
+// or infinite float anywhere inside it, so one odd field doesn't fail a dump.
+func (f FieldValue) MarshalJSON() ([]byte, error) {
+	value, err := json.Marshal(f.Value)
+	var unsupported *json.UnsupportedValueError
+	if errors.As(err, &unsupported) {
+		value, err = json.Marshal(fmt.Sprint(f.Value))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		Name  string          `json:"name"`
+		Value json.RawMessage `json:"value"`
+	}{f.Name, value})
+}
+
+// EntityReference is an EntityID-typed field pointing at another entity.
+type EntityReference struct {
+	Component string   `json:"component"`
+	Field     string   `json:"field"`
+	Target    EntityID `json:"target"`
+}
+
+func (w *World) Inspect(out io.Writer, format InspectFormat) error {
+	switch format {
+	case InspectText:
+		return w.inspectText(out)
+	case InspectJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			Archetypes []ArchetypeSnapshot `json:"archetypes"`
+		}{w.Archetypes()})
+	case InspectDOT:
+		return w.inspectDOT(out)
+	}
+	return fmt.Errorf("unknown inspect format %d", format)
+}
+
+// Archetypes describes every non-empty archetype and its entities, including
+// their sparse-set components, in query order.
+func (w *World) Archetypes() []ArchetypeSnapshot {
+	archetypes := make([]ArchetypeSnapshot, 0, len(w.archetypeOrder))
+	for _, archetype := range w.archetypeOrder {
+		if archetype.len() == 0 {
+			continue
+		}
+		snapshot := ArchetypeSnapshot{
+			Bitset:     archetype.bitset,
+			Components: componentNames(archetype.bitset),
+			Entities:   make([]EntitySnapshot, 0, archetype.len()),
+		}
+		for _, entity := range archetype.entities.all() {
+			snapshot.Entities = append(snapshot.Entities, w.InspectEntity(entity))
+		}
+		archetypes = append(archetypes, snapshot)
+	}
+	return archetypes
+}
+
+func (w *World) InspectEntity(entity EntityID) EntitySnapshot {
+	snapshot := EntitySnapshot{ID: entity, Components: make([]ComponentSnapshot, 0)}
+	for _, componentID := range w.ComponentsOf(entity) {
+		component, _ := w.componentValue(entity, componentID)
+		name := componentName(componentID)
+		fields, references := inspectFields(name, component)
+		snapshot.Components = append(snapshot.Components, ComponentSnapshot{
+			ID:       componentID,
+			Name:     name,
+			Disabled: w.isDisabled(entity, componentID),
+			Sparse:   Registry.isSparse(componentID),
+			Fields:   fields,
+		})
+		snapshot.References = append(snapshot.References, references...)
+	}
+	return snapshot
+}
+
+func (w *World) inspectText(out io.Writer) error {
+	var b strings.Builder
+	for _, archetype := range w.Archetypes() {
+		fmt.Fprintf(&b, "Archetype [%s] (%d entities)\n", strings.Join(archetype.Components, ", "), len(archetype.Entities))
+		for _, entity := range archetype.Entities {
+			fmt.Fprintf(&b, "  Entity %d\n", entity.ID)
+			for _, component := range entity.Components {
+				fields := make([]string, 0, len(component.Fields))
+				for _, field := range component.Fields {
+					fields = append(fields, fmt.Sprintf("%s: %v", field.Name, field.Value))
+				}
+				fmt.Fprintf(&b, "    %s {%s}", component.Name, strings.Join(fields, ", "))
+				if component.Sparse {
+					b.WriteString(" [sparse]")
+				}
+				if component.Disabled {
+					b.WriteString(" [disabled]")
+				}
+				b.WriteString("\n")
+			}
+			for _, reference := range entity.References {
+				fmt.Fprintf(&b, "    %s.%s -> Entity %d\n", reference.Component, reference.Field, reference.Target)
+			}
+		}
+	}
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func (w *World) inspectDOT(out io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph archetypes {\n  node [shape=box];\n")
+	for _, archetype := range w.archetypeOrder {
+		label := strings.Join(componentNames(archetype.bitset), "\\n")
+		if label == "" {
+			label = "(empty)"
+		}
+		fmt.Fprintf(&b, "  a%d [label=\"%s\\n%d entities\"];\n", archetype.bitset, label, archetype.len())
+	}
+	for _, from := range w.archetypeOrder {
+		for _, to := range w.archetypeOrder {
+			added := to.bitset.Without(from.bitset)
+			if !to.bitset.Has(from.bitset) || len(added.IDs()) != 1 {
+				continue
+			}
+			fmt.Fprintf(&b, "  a%d -> a%d [label=\"+%s\"];\n", from.bitset, to.bitset, componentName(added.IDs()[0]))
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func componentName(componentID ComponentID) string {
+	if componentType, exists := Registry.idToType[componentID]; exists {
+		return componentType.Name()
+	}
+	return fmt.Sprintf("Component%d", componentID)
+}
+
+func componentNames(bitset Bitset) []string {
+	names := make([]string, 0)
+	for _, componentID := range bitset.IDs() {
+		names = append(names, componentName(componentID))
+	}
+	return names
+}
+
+var entityIDType = reflect.TypeFor[EntityID]()
+
+// inspectFields lists a component's fields, reading unexported ones through
+// fmt since reflection can't hand them out as interfaces.
+func inspectFields(componentName string, component interface{}) ([]FieldValue, []EntityReference) {
+	value := reflect.ValueOf(component)
+	if !value.IsValid() {
+		return nil, nil
+	}
+	if value.Kind() != reflect.Struct {
+		return []FieldValue{{Name: "value", Value: fieldInterface(value)}}, nil
+	}
+
+	fields := make([]FieldValue, 0, value.NumField())
+	var references []EntityReference
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		fields = append(fields, FieldValue{Name: field.Name, Value: fieldInterface(value.Field(i))})
+		if field.Type == entityIDType {
+			references = append(references, EntityReference{
+				Component: componentName,
+				Field:     field.Name,
+				Target:    EntityID(value.Field(i).Uint()),
+			})
+		}
+	}
+	return fields, references
+}
+
+func fieldInterface(value reflect.Value) interface{} {
+	if value.CanInterface() {
+		return value.Interface()
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,115 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestWorld_Inspect(t *testing.T) {
+	w := NewWorld()
+	hero := w.CreateEntity()
+	w.AddComponents(hero, CharacterComponent{name: "hero"}, PositionComponent{x: 1, y: 2})
+	w.DisableComponent(hero, GetComponentID[PositionComponent]())
+	sidekick := w.CreateEntity()
+	w.AddComponents(sidekick, CharacterComponent{name: "sidekick"}, followComponent{target: hero}, selectedComponent{Order: 1})
+
+	t.Run("text", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := w.Inspect(&out, InspectText); err != nil {
+			t.Fatal(err)
+		}
+		for _, expected := range []string{
+			"Archetype [CharacterComponent, PositionComponent] (1 entities)\n  Entity 0\n",
+			"    CharacterComponent {name: hero}\n",
+			"    PositionComponent {x: 1, y: 2} [disabled]\n",
+			"    selectedComponent {Order: 1} [sparse]\n",
+			"    followComponent.target -> Entity 0\n",
+		} {
+			if !strings.Contains(out.String(), expected) {
+				t.Errorf("expected output to contain %q, got:\n%s", expected, out.String())
+			}
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := w.Inspect(&out, InspectJSON); err != nil {
+			t.Fatal(err)
+		}
+		var decoded struct {
+			Archetypes []ArchetypeSnapshot `json:"archetypes"`
+		}
+		if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+		}
+		if len(decoded.Archetypes) != 2 {
+			t.Fatalf("expected 2 archetypes, got %d", len(decoded.Archetypes))
+		}
+		sidekick := decoded.Archetypes[1].Entities[0]
+		if len(sidekick.References) != 1 || sidekick.References[0].Target != 0 {
+			t.Errorf("expected a reference to entity 0, got %+v", sidekick.References)
+		}
+		names := map[string]interface{}{}
+		for _, component := range sidekick.Components {
+			names[component.Name] = component.Fields[0].Value
+		}
+		if len(names) != 3 || names["CharacterComponent"] != "sidekick" {
+			t.Errorf("unexpected components %+v", sidekick.Components)
+		}
+	})
+}
+
+type gaugeComponent struct {
+	Level   float64
+	Samples [2]float64
+}
+
+var _ = RegisterComponent[gaugeComponent]()
+
+func TestWorld_InspectJSONNonFiniteFloats(t *testing.T) {
+	w := NewWorld()
+	w.AddComponents(w.CreateEntity(), gaugeComponent{Level: math.NaN(), Samples: [2]float64{1, math.Inf(-1)}})
+
+	var out bytes.Buffer
+	if err := w.Inspect(&out, InspectJSON); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Archetypes []ArchetypeSnapshot `json:"archetypes"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	fields := decoded.Archetypes[0].Entities[0].Components[0].Fields
+	if fields[0].Value != "NaN" || fields[1].Value != "[1 -Inf]" {
+		t.Errorf("expected non-finite floats as text, got %+v", fields)
+	}
+}
+
+func TestWorld_InspectDOT(t *testing.T) {
+	w := NewWorld()
+	entity := w.CreateEntity()
+	w.AddComponents(entity, CharacterComponent{})
+	w.AddComponents(entity, PositionComponent{})
+
+	var out bytes.Buffer
+	if err := w.Inspect(&out, InspectDOT); err != nil {
+		t.Fatal(err)
+	}
+	character := Bitset(0).AddID(GetComponentID[CharacterComponent]())
+	both := character.AddID(GetComponentID[PositionComponent]())
+	for _, expected := range []string{
+		"digraph archetypes {",
+		"[label=\"CharacterComponent\\n0 entities\"];",
+		"[label=\"CharacterComponent\\nPositionComponent\\n1 entities\"];",
+		fmt.Sprintf("a%d -> a%d [label=\"+PositionComponent\"];", character, both),
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, out.String())
+		}
+	}
+}
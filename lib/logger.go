@@ -0,0 +1,55 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// logger is the default for worlds created after SetLogger.
+var logger = slog.New(discardHandler{})
+
+// SetLogger sets the logger for worlds created after the call; worlds that
+// already exist keep theirs, use World.SetLogger to change those. Cache hits
+// and structural changes are logged at debug level; nil restores the
+// default, which discards everything.
+func SetLogger(l *slog.Logger) {
+	logger = orDiscard(l)
+}
+
+func (w *World) SetLogger(l *slog.Logger) {
+	w.logger = orDiscard(l)
+}
+
+func (w *World) debugEnabled() bool {
+	return w.logger.Enabled(context.Background(), slog.LevelDebug)
+}
+
+func (b Bitset) LogValue() slog.Value {
+	return slog.StringValue(fmt.Sprintf("%b", uint64(b)))
+}
+
+// loggedComponents logs an archetype bitset as the names of its components.
+type loggedComponents Bitset
+
+func (b loggedComponents) LogValue() slog.Value {
+	return slog.AnyValue(componentNames(Bitset(b)))
+}
+
+func (k QueryCacheKey) LogValue() slog.Value {
+	return slog.GroupValue(slog.Any("required", k.required), slog.Any("forbidden", k.forbidden))
+}
+
+func orDiscard(l *slog.Logger) *slog.Logger {
+	if l == nil {
+		return slog.New(discardHandler{})
+	}
+	return l
+}
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
@@ -0,0 +1,45 @@
+package lib
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWorld_SetLogger(t *testing.T) {
+	var buffer bytes.Buffer
+	w := NewWorld()
+	w.SetLogger(slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	entity := w.CreateEntity()
+	w.AddComponents(entity, PositionComponent{})
+	w.Query().With(GetComponentID[PositionComponent]()).Get()
+	w.Query().With(GetComponentID[PositionComponent]()).Get()
+	w.DestroyEntity(entity)
+
+	output := buffer.String()
+	for _, expected := range []string{
+		"msg=\"archetype created\" archetype=",
+		"msg=\"entity moved\" entity=0 from=0 to=",
+		"msg=\"query cache miss\" query.required=",
+		"msg=\"query cache hit\"",
+		"msg=\"entity destroyed\"",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected log output to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if count := strings.Count(output, "components=[PositionComponent]\n"); count != 2 {
+		t.Errorf("expected archetype created and entity moved to name their components, got:\n%s", output)
+	}
+
+	buffer.Reset()
+	w.SetLogger(slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	w.Query().With(GetComponentID[PositionComponent]()).Get()
+	w.SetLogger(nil)
+	w.Query().With(GetComponentID[PositionComponent]()).Get()
+	if buffer.Len() != 0 {
+		t.Errorf("expected debug events to be filtered, got:\n%s", buffer.String())
+	}
+}
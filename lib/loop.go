@@ -0,0 +1,95 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+)
+
+// RenderSystem runs once per frame after the fixed steps. alpha is how far
+// the frame sits between the last simulated tick and the next one, in [0, 1),
+// for interpolating positions.
+type RenderSystem interface {
+	Render(w *World, alpha float64)
+}
+
+// Loop drives a World with a fixed simulation step: frame time accumulates
+// and World.Update always receives exactly Step seconds, so the simulation
+// depends only on the number of ticks, not on frame-time jitter.
+type Loop struct {
+	world         *World
+	step          float64
+	maxSteps      int
+	accumulator   float64
+	alpha         float64
+	ticks         uint64
+	renderSystems []RenderSystem
+}
+
+// NewLoop panics unless step is a positive, finite number of seconds; any
+// other step would make Advance run forever or never.
+func NewLoop(world *World, step float64) *Loop {
+	if !(step > 0) || math.IsInf(step, 1) {
+		panic(fmt.Sprintf("loop step must be positive and finite, got %v", step))
+	}
+	return &Loop{
+		world:    world,
+		step:     step,
+		maxSteps: 5,
+	}
+}
+
+// SetMaxSteps caps how many ticks one frame may catch up on. Time beyond the
+// cap is dropped so a slow frame can't snowball into slower ones. It panics
+// unless maxSteps is at least 1.
+func (l *Loop) SetMaxSteps(maxSteps int) {
+	if maxSteps < 1 {
+		panic(fmt.Sprintf("loop max steps must be at least 1, got %d", maxSteps))
+	}
+	l.maxSteps = maxSteps
+}
+
+func (l *Loop) AddRenderSystem(system RenderSystem) {
+	l.renderSystems = append(l.renderSystems, system)
+}
+
+// Advance consumes one frame's worth of time, runs as many fixed steps as fit
+// and then the render systems. It returns the number of steps run. A negative
+// or non-finite frame time counts as 0 so it can't poison the accumulator.
+func (l *Loop) Advance(frameTime float64) int {
+	if !(frameTime > 0) || math.IsInf(frameTime, 1) {
+		frameTime = 0
+	}
+	l.accumulator += frameTime
+	steps := 0
+	for l.accumulator >= l.step && steps < l.maxSteps {
+		l.world.Update(l.step)
+		l.accumulator -= l.step
+		l.ticks++
+		steps++
+	}
+	if steps == l.maxSteps && l.accumulator >= l.step {
+		l.accumulator = 0
+	}
+
+	l.alpha = l.accumulator / l.step
+	for _, system := range l.renderSystems {
+		system.Render(l.world, l.alpha)
+	}
+	return steps
+}
+
+func (l *Loop) Alpha() float64 {
+	return l.alpha
+}
+
+func (l *Loop) Ticks() uint64 {
+	return l.ticks
+}
+
+func (l *Loop) Step() float64 {
+	return l.step
+}
+
+func (l *Loop) World() *World {
+	return l.world
+}
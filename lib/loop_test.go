@@ -0,0 +1,145 @@
+package lib
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+type velocityComponent struct {
+	dx, dy float64
+}
+
+var _ = RegisterComponent[velocityComponent]()
+
+type moveSystem struct{}
+
+func (moveSystem) Update(w *World, deltaTime float64) {
+	for entity, row := range w.Query().With(GetComponentID[velocityComponent]()).All() {
+		position := Component[PositionComponent](row)
+		velocity := Component[velocityComponent](row)
+		position.x += velocity.dx * deltaTime
+		position.y += velocity.dy * deltaTime
+		w.AddComponents(entity, position)
+	}
+}
+
+type alphaRecorder struct {
+	alphas []float64
+}
+
+func (r *alphaRecorder) Render(_ *World, alpha float64) {
+	r.alphas = append(r.alphas, alpha)
+}
+
+func positions(w *World) []PositionComponent {
+	result := make([]PositionComponent, 0)
+	for _, position := range Values[PositionComponent](w.Query()) {
+		result = append(result, position)
+	}
+	return result
+}
+
+func TestLoop_IdenticalResultsRegardlessOfJitter(t *testing.T) {
+	const ticks = 600
+	step := 1.0 / 60
+
+	build := func() *World {
+		w := NewWorld()
+		w.AddSystem(moveSystem{})
+		for i := 0; i < 10; i++ {
+			w.AddComponents(w.CreateEntity(), PositionComponent{}, velocityComponent{dx: float64(i), dy: 0.3})
+		}
+		return w
+	}
+
+	random := rand.New(rand.NewSource(3))
+	jittery := NewLoop(build(), step)
+	jittery.SetMaxSteps(1000)
+	for jittery.Ticks() < ticks {
+		jittery.Advance(random.Float64() * step * 3)
+	}
+
+	steady := NewLoop(build(), step)
+	for steady.Ticks() < jittery.Ticks() {
+		steady.Advance(step)
+	}
+
+	expected, got := positions(steady.world), positions(jittery.world)
+	for i := range expected {
+		if expected[i] != got[i] {
+			t.Fatalf("entity %d: expected %+v, got %+v", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestLoop_MaxCatchUpSteps(t *testing.T) {
+	loop := NewLoop(NewWorld(), 0.01)
+	loop.SetMaxSteps(3)
+
+	if steps := loop.Advance(1); steps != 3 {
+		t.Errorf("expected 3 catch-up steps, got %d", steps)
+	}
+	if steps := loop.Advance(0.015); steps != 1 {
+		t.Errorf("expected backlog to be dropped after a capped frame, got %d steps", steps)
+	}
+}
+
+func TestLoop_Alpha(t *testing.T) {
+	recorder := &alphaRecorder{}
+	loop := NewLoop(NewWorld(), 0.25)
+	loop.AddRenderSystem(recorder)
+
+	for _, frameTime := range []float64{0.125, 0.25, 0.375, 0.5} {
+		loop.Advance(frameTime)
+	}
+	expected := []float64{0.5, 0.5, 0, 0}
+	for i, alpha := range expected {
+		if recorder.alphas[i] != alpha {
+			t.Errorf("frame %d: expected alpha %v, got %v", i, alpha, recorder.alphas[i])
+		}
+	}
+	if loop.Ticks() != 5 {
+		t.Errorf("expected 5 ticks, got %d", loop.Ticks())
+	}
+}
+
+func TestNewLoop_InvalidStepPanics(t *testing.T) {
+	for _, step := range []float64{0, -0.01, math.NaN(), math.Inf(1)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic for step %v", step)
+				}
+			}()
+			NewLoop(NewWorld(), step)
+		}()
+	}
+}
+
+func TestLoop_SetMaxStepsRejectsNonPositive(t *testing.T) {
+	for _, maxSteps := range []int{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic for max steps %d", maxSteps)
+				}
+			}()
+			NewLoop(NewWorld(), 0.01).SetMaxSteps(maxSteps)
+		}()
+	}
+}
+
+func TestLoop_IgnoresInvalidFrameTimes(t *testing.T) {
+	loop := NewLoop(NewWorld(), 0.25)
+	loop.Advance(0.125)
+
+	for _, frameTime := range []float64{math.NaN(), -1, math.Inf(1), math.Inf(-1)} {
+		if steps := loop.Advance(frameTime); steps != 0 || loop.Alpha() != 0.5 {
+			t.Errorf("frame time %v: expected no steps and alpha 0.5, got %d steps and alpha %v", frameTime, steps, loop.Alpha())
+		}
+	}
+	if steps := loop.Advance(0.125); steps != 1 || loop.Alpha() != 0 {
+		t.Errorf("expected the loop to keep running after invalid frames, got %d steps and alpha %v", steps, loop.Alpha())
+	}
+}
@@ -0,0 +1,101 @@
+package lib
+
+import (
+	"iter"
+	"slices"
+)
+
+const pageSize = 256
+
+// pages is a growable array kept in fixed-size pages so a Snapshot can share
+// it. Sharing is O(1); afterwards the page table is copied on the next write
+// and each page on its own first write, so a snapshot costs memory in
+// proportion to what changes after it rather than to the size of the array.
+type pages[T any] struct {
+	table       [][]T
+	owned       []bool
+	length      int
+	sharedTable bool
+}
+
+// newPages reserves page table room for capacity elements.
+func newPages[T any](capacity int) pages[T] {
+	count := (capacity + pageSize - 1) / pageSize
+	return pages[T]{table: make([][]T, 0, count), owned: make([]bool, 0, count)}
+}
+
+func (p *pages[T]) len() int {
+	return p.length
+}
+
+func (p *pages[T]) at(i int) T {
+	return p.table[i/pageSize][i%pageSize]
+}
+
+func (p *pages[T]) set(i int, value T) {
+	page := i / pageSize
+	p.ownPage(page)
+	p.table[page][i%pageSize] = value
+}
+
+func (p *pages[T]) append(value T) {
+	if p.length == len(p.table)*pageSize {
+		p.ownTable()
+		p.table = append(p.table, make([]T, pageSize))
+		p.owned = append(p.owned, true)
+	}
+	p.length++
+	p.set(p.length-1, value)
+}
+
+// grow extends the array with zero values up to length n.
+func (p *pages[T]) grow(n int) {
+	var zero T
+	for p.length < n {
+		p.append(zero)
+	}
+}
+
+// pop drops the last element. Its slot is cleared only if the page is
+// already private; a shared page still holds it for the snapshot anyway.
+func (p *pages[T]) pop() {
+	p.length--
+	if page := p.length / pageSize; !p.sharedTable && p.owned[page] {
+		var zero T
+		p.table[page][p.length%pageSize] = zero
+	}
+}
+
+func (p *pages[T]) all() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := 0; i < p.length; i++ {
+			if !yield(i, p.table[i/pageSize][i%pageSize]) {
+				return
+			}
+		}
+	}
+}
+
+// share returns a frozen copy for a Snapshot; both keep reading the same
+// pages until this side writes.
+func (p *pages[T]) share() pages[T] {
+	p.sharedTable = true
+	return *p
+}
+
+func (p *pages[T]) ownTable() {
+	if !p.sharedTable {
+		return
+	}
+	p.table = slices.Clone(p.table)
+	p.owned = make([]bool, len(p.table))
+	p.sharedTable = false
+}
+
+func (p *pages[T]) ownPage(page int) {
+	p.ownTable()
+	if !p.owned[page] {
+		p.table[page] = slices.Clone(p.table[page])
+		p.owned[page] = true
+	}
+}
@@ -0,0 +1,53 @@
+package lib
+
+import (
+	"slices"
+	"testing"
+)
+
+func pagesValues(p *pages[int]) []int {
+	values := make([]int, 0, p.len())
+	for _, value := range p.all() {
+		values = append(values, value)
+	}
+	return values
+}
+
+func TestPages_ShareKeepsEveryCopyIntact(t *testing.T) {
+	var live pages[int]
+	for i := 0; i < pageSize+1; i++ {
+		live.append(i)
+	}
+	first := live.share()
+	expectedFirst := pagesValues(&first)
+
+	live.set(0, -1)
+	live.pop()
+	live.append(-2)
+	second := live.share()
+	expectedSecond := pagesValues(&second)
+
+	// Restoring the first copy must not let writes leak into the second.
+	live = first.share()
+	live.pop()
+	live.append(-3)
+	live.set(1, -4)
+
+	if got := pagesValues(&first); !slices.Equal(got, expectedFirst) {
+		t.Errorf("first copy changed: %v", got[:2])
+	}
+	if got := pagesValues(&second); !slices.Equal(got, expectedSecond) {
+		t.Errorf("second copy changed: %v", got[pageSize:])
+	}
+	if got := live.at(pageSize); got != -3 || live.at(1) != -4 || live.at(0) != 0 {
+		t.Errorf("unexpected live values %d %d %d", live.at(0), live.at(1), got)
+	}
+}
+
+func TestPages_Grow(t *testing.T) {
+	var p pages[int]
+	p.grow(3 * pageSize / 2)
+	if p.len() != 3*pageSize/2 || len(p.table) != 2 || p.at(p.len()-1) != 0 {
+		t.Errorf("expected %d zero values on 2 pages, got %d on %d", 3*pageSize/2, p.len(), len(p.table))
+	}
+}
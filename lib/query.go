@@ -1,11 +1,27 @@
 package lib
 
-import "fmt"
+import (
+	"slices"
+	"sort"
+)
 
 type Query struct {
-	world     *World
-	required  Bitset
-	forbidden Bitset
+	world      *World
+	required   Bitset
+	forbidden  Bitset
+	orderBy    []queryOrder
+	groupBy    *queryGroup
+	predicates []queryPredicate
+}
+
+type queryOrder struct {
+	componentID ComponentID
+	less        func(a, b interface{}) bool
+}
+
+type queryGroup struct {
+	componentID ComponentID
+	key         func(interface{}) int
 }
 
 type QueryEntity struct {
@@ -13,9 +29,15 @@ type QueryEntity struct {
 	Components map[ComponentID]interface{}
 }
 
+type QueryGroup struct {
+	Key      int
+	Entities []QueryEntity
+}
+
 type QueryResult struct {
 	world    *World
 	Entities []QueryEntity
+	Groups   []QueryGroup
 }
 
 func (w *World) Query() *Query {
@@ -36,48 +58,112 @@ func (q *Query) Without(componentIDs ...ComponentID) *Query {
 	return q
 }
 
+// OrderBy sorts results by a component value. Calls chain as tie-breakers;
+// the component becomes required.
+func (q *Query) OrderBy(componentID ComponentID, less func(a, b interface{}) bool) *Query {
+	q.orderBy = append(q.orderBy, queryOrder{componentID: componentID, less: less})
+	return q.With(componentID)
+}
+
+// GroupBy buckets results by a key derived from a component value. Groups are
+// returned in ascending key order in QueryResult.Groups, and Entities is
+// ordered group by group.
+func (q *Query) GroupBy(componentID ComponentID, key func(interface{}) int) *Query {
+	q.groupBy = &queryGroup{componentID: componentID, key: key}
+	return q.With(componentID)
+}
+
 func (q *Query) CacheKey() QueryCacheKey {
 	return QueryCacheKey{required: q.required, forbidden: q.forbidden}
 }
 
 func (q *Query) Get() QueryResult {
+	var result QueryResult
+	if rows, narrowed := q.candidateRows(); narrowed {
+		result = q.fromRows(rows)
+	} else if len(q.predicates) > 0 {
+		result = q.fromRows(slices.Collect(q.scan()))
+	} else {
+		result = q.get()
+	}
+	if q.isOrdered() {
+		result = q.order(result)
+	}
+	return result
+}
+
+func (q *Query) get() QueryResult {
 	cacheKey := q.CacheKey()
 	if result := q.world.queryCache.Get(cacheKey); result != nil {
-		fmt.Printf("Cache hit for query:\r\n")
+		if q.world.debugEnabled() {
+			q.world.logger.Debug("query cache hit", "query", cacheKey, "entities", len(result.Entities))
+		}
 		return *result
 	}
-	entities := make([]QueryEntity, 0)
-	for bitset, archetype := range q.world.archetypes {
-		if !bitset.Has(q.required) || !bitset.DoesNotHave(q.forbidden) {
-			continue
+	result := q.fromRows(slices.Collect(q.scan()))
+	q.world.queryCache.Set(cacheKey, result)
+	if q.world.debugEnabled() {
+		q.world.logger.Debug("query cache miss", "query", cacheKey, "entities", len(result.Entities))
+	}
+	return result
+}
+
+func (q *Query) fromRows(rows []Row) QueryResult {
+	entities := make([]QueryEntity, 0, len(rows))
+	requiredIDs := q.required.IDs()
+	for _, row := range rows {
+		components := make(map[ComponentID]interface{}, len(requiredIDs))
+		for _, componentID := range requiredIDs {
+			components[componentID] = row.Get(componentID)
 		}
+		entities = append(entities, QueryEntity{ID: row.Entity(), Components: components})
+	}
+	return QueryResult{world: q.world, Entities: entities}
+}
+
+func (q *Query) isOrdered() bool {
+	return len(q.orderBy) > 0 || q.groupBy != nil
+}
 
-		for entityIndex, entity := range archetype.entities {
-			components := make(map[ComponentID]interface{})
-			disabledMask, exists := archetype.disabledMaskPerEntity[entity]
-			if !exists {
-				disabledMask = Bitset(0)
+// order sorts a copy of the entities, leaving the cached result untouched.
+func (q *Query) order(result QueryResult) QueryResult {
+	entities := append([]QueryEntity(nil), result.Entities...)
+	keys := make(map[EntityID]int)
+	if q.groupBy != nil {
+		for _, entity := range entities {
+			keys[entity.ID] = q.groupBy.key(entity.Components[q.groupBy.componentID])
+		}
+	}
+
+	sort.SliceStable(entities, func(i, j int) bool {
+		a, b := entities[i], entities[j]
+		if keys[a.ID] != keys[b.ID] {
+			return keys[a.ID] < keys[b.ID]
+		}
+		for _, order := range q.orderBy {
+			valueA, valueB := a.Components[order.componentID], b.Components[order.componentID]
+			if order.less(valueA, valueB) {
+				return true
 			}
-			oneOfTheQueriedComponentsIsDisabled := false
-			for componentID, componentArray := range archetype.components {
-				componentWasQueried := q.required.HasID(componentID)
-				disabled := disabledMask.HasID(componentID)
-				if componentWasQueried && disabled {
-					oneOfTheQueriedComponentsIsDisabled = true
-					break
-				}
-				if componentWasQueried && len(componentArray) > entityIndex {
-					components[componentID] = componentArray[entityIndex]
-				}
+			if order.less(valueB, valueA) {
+				return false
 			}
-			if !oneOfTheQueriedComponentsIsDisabled {
-				entities = append(entities, QueryEntity{ID: entity, Components: components})
+		}
+		return false
+	})
+
+	var groups []QueryGroup
+	if q.groupBy != nil {
+		for start := 0; start < len(entities); {
+			end := start + 1
+			for end < len(entities) && keys[entities[end].ID] == keys[entities[start].ID] {
+				end++
 			}
+			groups = append(groups, QueryGroup{Key: keys[entities[start].ID], Entities: entities[start:end]})
+			start = end
 		}
 	}
-	result := QueryResult{Entities: entities, world: q.world}
-	q.world.queryCache.Set(cacheKey, result)
-	return result
+	return QueryResult{world: result.world, Entities: entities, Groups: groups}
 }
 
 func (q *Query) Each(fn func(EntityID, map[ComponentID]interface{})) {
@@ -6,7 +6,9 @@ type QueryCacheKey struct {
 }
 
 type QueryCache struct {
-	cache map[QueryCacheKey]QueryResult
+	cache  map[QueryCacheKey]QueryResult
+	hits   int
+	misses int
 }
 
 func NewQueryCache() *QueryCache {
@@ -18,8 +20,10 @@ func NewQueryCache() *QueryCache {
 func (q *QueryCache) Get(key QueryCacheKey) *QueryResult {
 	value, exist := q.cache[key]
 	if exist {
+		q.hits++
 		return &value
 	}
+	q.misses++
 	return nil
 }
 
@@ -34,3 +38,7 @@ func (q *QueryCache) Invalidate(componentID ComponentID) {
 		}
 	}
 }
+
+func (q *QueryCache) Clear() {
+	clear(q.cache)
+}
@@ -0,0 +1,229 @@
+package lib
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+type queryPredicate struct {
+	componentID ComponentID
+	match       func(interface{}) bool
+
+	// Set for field equality predicates so an index on the field can answer
+	// them directly.
+	field string
+	value interface{}
+}
+
+// Where keeps entities whose T component satisfies fn. fn receives a pointer
+// to a copy, so writes through it are not stored. T becomes required.
+func Where[T any](q *Query, fn func(*T) bool) *Query {
+	componentID := GetComponentID[T]()
+	q.predicates = append(q.predicates, queryPredicate{
+		componentID: componentID,
+		match: func(component interface{}) bool {
+			value := component.(T)
+			return fn(&value)
+		},
+	})
+	return q.With(componentID)
+}
+
+// WhereEq keeps entities whose T component has field equal to value. Numbers
+// are converted to the field's type when that loses nothing, so untyped
+// constants like 2 match an int32 field. It panics if T has no such field or
+// value cannot be compared with it, like GetComponentID does for unregistered
+// types.
+func WhereEq[T any](q *Query, field string, value interface{}) *Query {
+	componentID := GetComponentID[T]()
+	fieldIndex, key := fieldKeyOf(Registry.idToType[componentID], field, value)
+	q.predicates = append(q.predicates, queryPredicate{
+		componentID: componentID,
+		match: func(component interface{}) bool {
+			return fieldValue(component, fieldIndex) == key
+		},
+		field: field,
+		value: key,
+	})
+	return q.With(componentID)
+}
+
+func (q *Query) archetypeMatches(archetype *Archetype) bool {
+	return archetype.bitset.Has(q.required&^Registry.sparse) &&
+		archetype.bitset.DoesNotHave(q.forbidden&^Registry.sparse)
+}
+
+// rowMatches checks a row of an archetype that already matches the query.
+func (q *Query) rowMatches(archetype *Archetype, row int) bool {
+	entity := archetype.entity(row)
+	if archetype.disabled.at(row)&q.required != 0 {
+		return false
+	}
+	for _, componentID := range ((q.required | q.forbidden) & Registry.sparse).IDs() {
+		_, present := q.world.sparseValue(entity, componentID)
+		if q.forbidden.HasID(componentID) && present {
+			return false
+		}
+		if q.required.HasID(componentID) && (!present || q.world.isDisabled(entity, componentID)) {
+			return false
+		}
+	}
+	for _, predicate := range q.predicates {
+		if !predicate.match(Row{world: q.world, archetype: archetype, index: row}.Get(predicate.componentID)) {
+			return false
+		}
+	}
+	return true
+}
+
+// candidateRows narrows the query to a short list of entities when an index
+// on one of its equality predicates, or one of its required sparse-set
+// components, can provide them. Rows come back in archetype then row order so
+// results don't depend on which path answered.
+func (q *Query) candidateRows() ([]Row, bool) {
+	candidates, narrowed := q.indexedEntities()
+	if !narrowed {
+		candidates, narrowed = q.sparseEntities()
+	}
+	if !narrowed {
+		return nil, false
+	}
+
+	archetypeOrder := make(map[*Archetype]int, len(q.world.archetypeOrder))
+	for i, archetype := range q.world.archetypeOrder {
+		archetypeOrder[archetype] = i
+	}
+	rows := make([]Row, 0)
+	for _, entity := range candidates {
+		archetype, row := q.world.entityRow(entity)
+		if row == -1 || !q.archetypeMatches(archetype) {
+			continue
+		}
+		if q.rowMatches(archetype, row) {
+			rows = append(rows, Row{world: q.world, archetype: archetype, index: row})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].archetype != rows[j].archetype {
+			return archetypeOrder[rows[i].archetype] < archetypeOrder[rows[j].archetype]
+		}
+		return rows[i].index < rows[j].index
+	})
+	return rows, true
+}
+
+func (q *Query) indexedEntities() ([]EntityID, bool) {
+	for _, predicate := range q.predicates {
+		if predicate.field == "" {
+			continue
+		}
+		if index := q.world.fieldIndex(predicate.componentID, predicate.field); index != nil {
+			return index.lookup(predicate.value), true
+		}
+	}
+	return nil, false
+}
+
+// sparseEntities starts from the smallest required sparse set.
+func (q *Query) sparseEntities() ([]EntityID, bool) {
+	var smallest *sparseSet
+	narrowed := false
+	for _, componentID := range (q.required & Registry.sparse).IDs() {
+		set, exists := q.world.sparseSets[componentID]
+		if !exists {
+			return nil, true
+		}
+		if !narrowed || set.len() < smallest.len() {
+			smallest, narrowed = set, true
+		}
+	}
+	if !narrowed {
+		return nil, false
+	}
+	entities := make([]EntityID, 0, smallest.len())
+	for _, entity := range smallest.entities.all() {
+		entities = append(entities, entity)
+	}
+	return entities, true
+}
+
+func fieldKeyOf(componentType reflect.Type, field string, value interface{}) ([]int, interface{}) {
+	structField, exists := componentType.FieldByName(field)
+	if !exists {
+		panic(fmt.Sprintf("component type %v has no field %s", componentType, field))
+	}
+	key, ok := convertKey(reflect.ValueOf(value), structField.Type)
+	if !ok {
+		panic(fmt.Sprintf("value %v (%T) cannot be compared with %v.%s", value, value, componentType, field))
+	}
+	return structField.Index, canonical(key)
+}
+
+// convertKey converts value to the field type the way an untyped constant
+// would: assignable values and values of the same kind pass as they are, and
+// numbers only if they survive the round trip, so 2.5 never matches an int
+// field and 65 never matches the string "A".
+func convertKey(value reflect.Value, fieldType reflect.Type) (reflect.Value, bool) {
+	if !value.IsValid() {
+		return reflect.Value{}, false
+	}
+	if value.Type().AssignableTo(fieldType) {
+		return value, true
+	}
+	if !isNumber(value.Kind()) || !isNumber(fieldType.Kind()) {
+		if value.Kind() == fieldType.Kind() && value.Type().ConvertibleTo(fieldType) {
+			return value.Convert(fieldType), true
+		}
+		return reflect.Value{}, false
+	}
+	converted := value.Convert(fieldType)
+	if !converted.Convert(value.Type()).Equal(value) {
+		return reflect.Value{}, false
+	}
+	// A negative int converted to an unsigned field wraps and back again, so
+	// compare signs too.
+	if negative(value) != negative(converted) {
+		return reflect.Value{}, false
+	}
+	return converted, true
+}
+
+func isNumber(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Float64
+}
+
+func negative(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() < 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() < 0
+	}
+	return false
+}
+
+func fieldValue(component interface{}, fieldIndex []int) interface{} {
+	return canonical(reflect.ValueOf(component).FieldByIndex(fieldIndex))
+}
+
+// canonical turns a field into a comparable value that works for unexported
+// fields too, widening numbers so field and key types don't need to match.
+func canonical(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	}
+	if !v.CanInterface() {
+		panic(fmt.Sprintf("unexported field of kind %v cannot be compared", v.Kind()))
+	}
+	return v.Interface()
+}
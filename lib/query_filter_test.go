@@ -0,0 +1,132 @@
+package lib
+
+import (
+	"reflect"
+	"testing"
+)
+
+type layerComponent struct {
+	Layer int32
+	Name  string
+	Mask  uint16
+}
+
+var _ = RegisterComponent[layerComponent]()
+
+func TestWhere(t *testing.T) {
+	w := NewWorld()
+	spawn(w, 12, func(i int) []interface{} {
+		return []interface{}{layerComponent{Layer: int32(i % 4)}, PositionComponent{x: float64(i)}}
+	})
+	query := Where(w.Query(), func(p *PositionComponent) bool { return p.x >= 6 })
+	query = Where(query, func(l *layerComponent) bool { return l.Layer != 3 })
+
+	got := make([]EntityID, 0)
+	query.Each(func(id EntityID, _ map[ComponentID]interface{}) {
+		got = append(got, id)
+	})
+	expected := []EntityID{6, 8, 9, 10}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v from Each, got %v", expected, got)
+	}
+
+	got = got[:0]
+	for entity := range query.Entities() {
+		got = append(got, entity)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v from All, got %v", expected, got)
+	}
+
+	all := 0
+	w.Query().With(GetComponentID[PositionComponent]()).Each(func(EntityID, map[ComponentID]interface{}) { all++ })
+	if all != 12 {
+		t.Errorf("expected predicates not to leak into cached results, got %d entities", all)
+	}
+}
+
+func TestWhereEq(t *testing.T) {
+	w := NewWorld()
+	spawn(w, 12, func(i int) []interface{} {
+		return []interface{}{layerComponent{Layer: int32(i % 4)}, PositionComponent{x: float64(i)}}
+	})
+
+	tests := []struct {
+		name     string
+		field    string
+		value    interface{}
+		expected []EntityID
+	}{
+		{"untyped constant", "Layer", 2, []EntityID{2, 6, 10}},
+		{"exact type", "Layer", int32(0), []EntityID{0, 4, 8}},
+		{"no match", "Layer", 7, []EntityID{}},
+		{"unexported field", "x", 5, []EntityID{5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query *Query
+			if tt.field == "x" {
+				query = WhereEq[PositionComponent](w.Query(), tt.field, tt.value)
+			} else {
+				query = WhereEq[layerComponent](w.Query(), tt.field, tt.value)
+			}
+			got := make([]EntityID, 0)
+			for entity := range query.Entities() {
+				got = append(got, entity)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWhereEq_LossyValuePanics(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		value interface{}
+	}{
+		{"fraction on int field", "Layer", 2.5},
+		{"int on string field", "Name", 65},
+		{"string on int field", "Layer", "2"},
+		{"out of range", "Layer", int64(1) << 40},
+		{"negative on unsigned field", "Mask", -1},
+		{"nil", "Layer", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic when the query is built")
+				}
+			}()
+			WhereEq[layerComponent](NewWorld().Query(), tt.field, tt.value)
+		})
+	}
+}
+
+func TestWhereEq_LosslessConversion(t *testing.T) {
+	w := NewWorld()
+	entity := w.CreateEntity()
+	w.AddComponents(entity, layerComponent{Layer: 2, Name: "A", Mask: 3})
+
+	for _, value := range []interface{}{2, 2.0, uint8(2)} {
+		count := 0
+		for range WhereEq[layerComponent](w.Query(), "Layer", value).Entities() {
+			count++
+		}
+		if count != 1 {
+			t.Errorf("expected %v (%T) to match, got %d entities", value, value, count)
+		}
+	}
+}
+
+func TestWhereEq_UnknownFieldPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic for an unknown field")
+		}
+	}()
+	WhereEq[layerComponent](NewWorld().Query(), "Depth", 1)
+}
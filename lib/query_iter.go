@@ -0,0 +1,118 @@
+package lib
+
+import (
+	"iter"
+	"reflect"
+)
+
+// Row points at one entity's components inside an archetype. It is only
+// valid until the next structural change to the world.
+type Row struct {
+	world     *World
+	archetype *Archetype
+	index     int
+}
+
+func (r Row) Entity() EntityID {
+	return r.archetype.entity(r.index)
+}
+
+func (r Row) Get(componentID ComponentID) interface{} {
+	if Registry.isSparse(componentID) {
+		component, _ := r.world.sparseValue(r.Entity(), componentID)
+		return component
+	}
+	return r.archetype.value(componentID, r.index)
+}
+
+func (r Row) Has(componentID ComponentID) bool {
+	if Registry.isSparse(componentID) {
+		return r.world.HasComponent(r.Entity(), componentID)
+	}
+	if !r.archetype.bitset.HasID(componentID) {
+		return false
+	}
+	return !r.archetype.disabled.at(r.index).HasID(componentID)
+}
+
+func Component[T any](r Row) T {
+	var zero T
+	component, _ := r.Get(Registry.typeToID[reflect.TypeOf(zero)]).(T)
+	return component
+}
+
+// All streams matching rows straight from archetype storage, in archetype
+// creation order, without building a result slice unless OrderBy or GroupBy
+// is set. Updating component values while ranging is fine; adding or removing
+// components or entities may skip or repeat rows, so collect those changes or
+// use Each instead.
+func (q *Query) All() iter.Seq2[EntityID, Row] {
+	return func(yield func(EntityID, Row) bool) {
+		if q.isOrdered() {
+			for _, entity := range q.Get().Entities {
+				archetype, row := q.world.entityRow(entity.ID)
+				if !yield(entity.ID, Row{world: q.world, archetype: archetype, index: row}) {
+					return
+				}
+			}
+			return
+		}
+		if candidates, narrowed := q.candidateRows(); narrowed {
+			for _, row := range candidates {
+				if !yield(row.Entity(), row) {
+					return
+				}
+			}
+			return
+		}
+		for row := range q.scan() {
+			if !yield(row.Entity(), row) {
+				return
+			}
+		}
+	}
+}
+
+// scan walks every archetype whose table components fit the query.
+func (q *Query) scan() iter.Seq[Row] {
+	return func(yield func(Row) bool) {
+		for _, archetype := range q.world.archetypeOrder {
+			if !q.archetypeMatches(archetype) {
+				continue
+			}
+			for row := 0; row < archetype.len(); row++ {
+				if !q.rowMatches(archetype, row) {
+					continue
+				}
+				if !yield(Row{world: q.world, archetype: archetype, index: row}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (q *Query) Entities() iter.Seq[EntityID] {
+	return func(yield func(EntityID) bool) {
+		for entity := range q.All() {
+			if !yield(entity) {
+				return
+			}
+		}
+	}
+}
+
+// Values streams the T component of every entity matching the query; T is
+// added to the required components.
+func Values[T any](q *Query) iter.Seq2[EntityID, T] {
+	componentID := GetComponentID[T]()
+	scoped := *q
+	scoped.With(componentID)
+	return func(yield func(EntityID, T) bool) {
+		for entity, row := range scoped.All() {
+			if !yield(entity, row.Get(componentID).(T)) {
+				return
+			}
+		}
+	}
+}
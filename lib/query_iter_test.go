@@ -0,0 +1,90 @@
+package lib
+
+import (
+	"testing"
+)
+
+// evenCharacters gives every entity a position and even ones a character.
+func evenCharacters(i int) []interface{} {
+	if i%2 == 0 {
+		return []interface{}{PositionComponent{x: float64(i)}, CharacterComponent{name: "even"}}
+	}
+	return []interface{}{PositionComponent{x: float64(i)}}
+}
+
+func TestQuery_AllMatchesEach(t *testing.T) {
+	w := NewWorld()
+	positionID := GetComponentID[PositionComponent]()
+	characterID := GetComponentID[CharacterComponent]()
+	for i, entity := range spawn(w, 100, evenCharacters) {
+		if i%10 == 0 {
+			w.DisableComponent(entity, positionID)
+		}
+	}
+
+	expected := map[EntityID]float64{}
+	w.Query().With(positionID).Without(characterID).Each(func(id EntityID, m map[ComponentID]interface{}) {
+		expected[id] = m[positionID].(PositionComponent).x
+	})
+
+	got := map[EntityID]float64{}
+	for entity, row := range w.Query().With(positionID).Without(characterID).All() {
+		got[entity] = Component[PositionComponent](row).x
+		if !row.Has(positionID) || row.Has(characterID) {
+			t.Errorf("unexpected row components for %v", entity)
+		}
+	}
+
+	if len(got) != len(expected) || len(got) != 50 {
+		t.Fatalf("expected %d entities, got %d", len(expected), len(got))
+	}
+	for entity, x := range expected {
+		if got[entity] != x {
+			t.Errorf("entity %v: expected x=%v, got %v", entity, x, got[entity])
+		}
+	}
+}
+
+func TestQuery_AllEarlyBreak(t *testing.T) {
+	w := NewWorld()
+	spawn(w, 100, evenCharacters)
+	visited := 0
+	for range w.Query().With(GetComponentID[PositionComponent]()).Entities() {
+		visited++
+		if visited == 3 {
+			break
+		}
+	}
+	if visited != 3 {
+		t.Errorf("expected to stop after 3 entities, visited %d", visited)
+	}
+}
+
+func TestValues(t *testing.T) {
+	w := NewWorld()
+	spawn(w, 100, evenCharacters)
+	count := 0
+	for entity, character := range Values[CharacterComponent](w.Query()) {
+		if character.name != "even" || entity%2 != 0 {
+			t.Errorf("unexpected character %v on entity %v", character, entity)
+		}
+		count++
+	}
+	if count != 50 {
+		t.Errorf("expected 50 characters, got %d", count)
+	}
+}
+
+func TestQuery_AllDoesNotAllocatePerRow(t *testing.T) {
+	w := NewWorld()
+	spawn(w, 100, evenCharacters)
+	query := w.Query().With(GetComponentID[PositionComponent]())
+	allocs := testing.AllocsPerRun(10, func() {
+		for _, row := range query.All() {
+			_ = row.Get(GetComponentID[PositionComponent]())
+		}
+	})
+	if allocs > 2 {
+		t.Errorf("expected constant allocations, got %v per run", allocs)
+	}
+}
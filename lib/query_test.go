@@ -0,0 +1,109 @@
+package lib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuery_DeterministicOrder(t *testing.T) {
+	w := NewWorld()
+	expected := make([]EntityID, 0)
+	for i := 0; i < 50; i++ {
+		entity := w.CreateEntity()
+		switch i % 3 {
+		case 0:
+			w.AddComponents(entity, PositionComponent{})
+		case 1:
+			w.AddComponents(entity, PositionComponent{}, CharacterComponent{})
+		case 2:
+			w.AddComponents(entity, PositionComponent{}, IsEnabledComponent{})
+		}
+	}
+	for i := 0; i < 3; i++ {
+		for entity := EntityID(i); entity < 50; entity += 3 {
+			expected = append(expected, entity)
+		}
+	}
+
+	positionID := GetComponentID[PositionComponent]()
+	for run := 0; run < 20; run++ {
+		w.queryCache.Invalidate(positionID)
+		got := make([]EntityID, 0)
+		w.Query().With(positionID).Each(func(id EntityID, _ map[ComponentID]interface{}) {
+			got = append(got, id)
+		})
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("run %d: expected %v, got %v", run, expected, got)
+		}
+
+		got = got[:0]
+		for entity := range w.Query().With(positionID).Entities() {
+			got = append(got, entity)
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("run %d: expected iterator order %v, got %v", run, expected, got)
+		}
+	}
+}
+
+func TestQuery_OrderBy(t *testing.T) {
+	w := NewWorld()
+	for _, name := range []string{"c", "a", "b", "a"} {
+		entity := w.CreateEntity()
+		w.AddComponents(entity, CharacterComponent{name: name}, PositionComponent{x: float64(entity)})
+	}
+
+	characterID := GetComponentID[CharacterComponent]()
+	positionID := GetComponentID[PositionComponent]()
+	query := w.Query().
+		OrderBy(characterID, func(a, b interface{}) bool {
+			return a.(CharacterComponent).name < b.(CharacterComponent).name
+		}).
+		OrderBy(positionID, func(a, b interface{}) bool {
+			return a.(PositionComponent).x > b.(PositionComponent).x
+		})
+
+	got := make([]EntityID, 0)
+	for entity := range query.Entities() {
+		got = append(got, entity)
+	}
+	expected := []EntityID{3, 1, 2, 0}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	unordered := w.Query().With(characterID).Get()
+	if unordered.Entities[0].ID != 0 {
+		t.Errorf("expected ordering not to leak into the cached result, got %v first", unordered.Entities[0].ID)
+	}
+}
+
+func TestQuery_GroupBy(t *testing.T) {
+	w := NewWorld()
+	for i := 0; i < 9; i++ {
+		entity := w.CreateEntity()
+		w.AddComponents(entity, PositionComponent{x: float64(i), y: float64(2 - i%3)})
+	}
+
+	positionID := GetComponentID[PositionComponent]()
+	result := w.Query().
+		GroupBy(positionID, func(c interface{}) int { return int(c.(PositionComponent).y) }).
+		Get()
+
+	if len(result.Groups) != 3 {
+		t.Fatalf("expected 3 groups, got %d", len(result.Groups))
+	}
+	for key, group := range result.Groups {
+		if group.Key != key || len(group.Entities) != 3 {
+			t.Errorf("expected group %d with 3 entities, got %d with %d", key, group.Key, len(group.Entities))
+		}
+		for _, entity := range group.Entities {
+			if int(entity.Components[positionID].(PositionComponent).y) != key {
+				t.Errorf("entity %v in wrong group %d", entity.ID, key)
+			}
+		}
+	}
+	if result.Entities[0].ID != 2 || result.Entities[8].ID != 6 {
+		t.Errorf("expected entities ordered by group then row, got %v", result.Entities)
+	}
+}
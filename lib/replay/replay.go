@@ -0,0 +1,263 @@
+// Package replay records the input a session feeds into a fixed-step World
+// and plays it back, so a run can be reproduced tick for tick.
+//
+// Gameplay systems read input through an InputSystem instead of polling the
+// window, read the screen through a Player when replaying, and draw
+// randomness from a generator seeded with Recording.Seed. Given the same
+// seed, the same inputs and the same screen per tick, a World driven by
+// lib.Loop ends up in the same state.
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+
+	"ecs/lib"
+)
+
+type Buttons uint8
+
+const (
+	ButtonLeft Buttons = 1 << iota
+	ButtonRight
+	ButtonMiddle
+)
+
+// Input is everything the simulation reads from the player in one tick.
+// Pressed holds the buttons that went down this tick, Down those held.
+type Input struct {
+	MouseX  float64 `json:"x"`
+	MouseY  float64 `json:"y"`
+	Down    Buttons `json:"down,omitempty"`
+	Pressed Buttons `json:"pressed,omitempty"`
+}
+
+// Source produces the input for the next tick. Poll is called exactly once
+// per tick.
+type Source interface {
+	Poll() Input
+}
+
+// ScreenSource reports the size and DPI scale the simulation renders at.
+type ScreenSource interface {
+	RenderSize() (width, height int32)
+	ScaleDPI() float32
+}
+
+// Screen is the render size and DPI scale in effect for a tick.
+type Screen struct {
+	Width  int32   `json:"width"`
+	Height int32   `json:"height"`
+	DPI    float32 `json:"dpi"`
+}
+
+func (s Screen) RenderSize() (int32, int32) {
+	return s.Width, s.Height
+}
+
+func (s Screen) ScaleDPI() float32 {
+	return s.DPI
+}
+
+// ScreenChange records that the screen became Screen at Tick, counting from
+// the first recorded tick.
+type ScreenChange struct {
+	Tick int `json:"tick"`
+	Screen
+}
+
+// Recording holds the input of every tick, and the screen each time it
+// changed, starting with the screen at tick 0. Hash is the World.Hash the
+// session ended with.
+type Recording struct {
+	Seed    int64          `json:"seed"`
+	Step    float64        `json:"step"`
+	Inputs  []Input        `json:"inputs"`
+	Screens []ScreenChange `json:"screens"`
+	Hash    uint64         `json:"hash"`
+}
+
+// Rand returns a generator in the state the recorded session started with.
+func (r *Recording) Rand() *rand.Rand {
+	return rand.New(rand.NewSource(r.Seed))
+}
+
+func (r *Recording) Save(out io.Writer) error {
+	return json.NewEncoder(out).Encode(r)
+}
+
+func (r *Recording) SaveFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.Save(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func Load(in io.Reader) (*Recording, error) {
+	var recording Recording
+	if err := json.NewDecoder(in).Decode(&recording); err != nil {
+		return nil, fmt.Errorf("decoding recording: %w", err)
+	}
+	if recording.Step <= 0 {
+		return nil, fmt.Errorf("decoding recording: invalid step %v", recording.Step)
+	}
+	if len(recording.Screens) == 0 || recording.Screens[0].Tick != 0 {
+		return nil, fmt.Errorf("decoding recording: no screen recorded for tick 0")
+	}
+	for i, change := range recording.Screens {
+		if i > 0 && change.Tick <= recording.Screens[i-1].Tick {
+			return nil, fmt.Errorf("decoding recording: screen changes out of order at tick %d", change.Tick)
+		}
+		if change.Width <= 0 || change.Height <= 0 || change.DPI <= 0 {
+			return nil, fmt.Errorf("decoding recording: invalid screen %+v at tick %d", change.Screen, change.Tick)
+		}
+	}
+	return &recording, nil
+}
+
+func LoadFile(path string) (*Recording, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Load(file)
+}
+
+// Recorder passes input through from a live source and keeps a copy of every
+// tick, along with the screen whenever it differs from the previous tick.
+type Recorder struct {
+	source    Source
+	screen    ScreenSource
+	recording Recording
+}
+
+func NewRecorder(source Source, screen ScreenSource, seed int64, step float64) *Recorder {
+	return &Recorder{
+		source:    source,
+		screen:    screen,
+		recording: Recording{Seed: seed, Step: step},
+	}
+}
+
+func (r *Recorder) Poll() Input {
+	width, height := r.screen.RenderSize()
+	screen := Screen{Width: width, Height: height, DPI: r.screen.ScaleDPI()}
+	screens := r.recording.Screens
+	if len(screens) == 0 || screens[len(screens)-1].Screen != screen {
+		r.recording.Screens = append(screens, ScreenChange{Tick: len(r.recording.Inputs), Screen: screen})
+	}
+
+	input := r.source.Poll()
+	r.recording.Inputs = append(r.recording.Inputs, input)
+	return input
+}
+
+// Finish stamps the recording with the hash of world, which must have run
+// exactly the recorded ticks, and returns it.
+func (r *Recorder) Finish(world *lib.World) *Recording {
+	r.recording.Hash = world.Hash()
+	return &r.recording
+}
+
+// Player feeds a recording back one tick at a time. Once it runs out it
+// keeps returning the last input with no buttons pressed.
+//
+// Player is also the screen for the replayed World: RenderSize and ScaleDPI
+// report the recorded screen of the tick last polled.
+type Player struct {
+	recording *Recording
+	tick      int
+	screen    int
+}
+
+func NewPlayer(recording *Recording) *Player {
+	return &Player{recording: recording}
+}
+
+func (p *Player) Poll() Input {
+	inputs := p.recording.Inputs
+	if p.tick >= len(inputs) {
+		if len(inputs) == 0 {
+			return Input{}
+		}
+		last := inputs[len(inputs)-1]
+		return Input{MouseX: last.MouseX, MouseY: last.MouseY}
+	}
+	input := inputs[p.tick]
+	screens := p.recording.Screens
+	for p.screen+1 < len(screens) && screens[p.screen+1].Tick <= p.tick {
+		p.screen++
+	}
+	p.tick++
+	return input
+}
+
+func (p *Player) RenderSize() (int32, int32) {
+	return p.current().RenderSize()
+}
+
+func (p *Player) ScaleDPI() float32 {
+	return p.current().ScaleDPI()
+}
+
+func (p *Player) current() Screen {
+	if len(p.recording.Screens) == 0 {
+		return Screen{}
+	}
+	return p.recording.Screens[p.screen].Screen
+}
+
+func (p *Player) Done() bool {
+	return p.tick >= len(p.recording.Inputs)
+}
+
+// InputSystem polls its source once per tick so every system reading Current
+// within the tick sees the same input. Add it before those systems.
+type InputSystem struct {
+	source  Source
+	current Input
+}
+
+func NewInputSystem(source Source) *InputSystem {
+	return &InputSystem{source: source}
+}
+
+func (s *InputSystem) Update(_ *lib.World, _ float64) {
+	s.current = s.source.Poll()
+}
+
+func (s *InputSystem) Current() Input {
+	return s.current
+}
+
+// Replay advances the loop one step per frame until the player has fed every
+// recorded tick. The loop's World must be set up the same way as in the
+// recorded session, with an InputSystem reading from the player, and step at
+// the recorded step. If the step differs, the inputs aren't all consumed
+// within as many ticks as were recorded, or the world ends with a different
+// hash than the recorded one, it returns an error.
+func Replay(loop *lib.Loop, player *Player) error {
+	if loop.Step() != player.recording.Step {
+		return fmt.Errorf("replay: loop steps by %v but the recording stepped by %v", loop.Step(), player.recording.Step)
+	}
+	ticks := len(player.recording.Inputs)
+	for tick := 0; tick < ticks && !player.Done(); tick++ {
+		loop.Advance(loop.Step())
+	}
+	if !player.Done() {
+		return fmt.Errorf("replay: %d of %d recorded inputs consumed after %d ticks", player.tick, ticks, ticks)
+	}
+	if hash := loop.World().Hash(); hash != player.recording.Hash {
+		return fmt.Errorf("replay: world hash %016x after %d ticks, recorded %016x", hash, ticks, player.recording.Hash)
+	}
+	return nil
+}
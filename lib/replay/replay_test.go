@@ -0,0 +1,225 @@
+package replay
+
+import (
+	"bytes"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"ecs/lib"
+)
+
+type Position struct {
+	X, Y float64
+}
+
+type Velocity struct {
+	X, Y float64
+}
+
+var positionID = lib.RegisterComponent[Position]()
+var velocityID = lib.RegisterComponent[Velocity]()
+
+type spawnSystem struct {
+	input  *InputSystem
+	screen ScreenSource
+	rng    *rand.Rand
+}
+
+func (s *spawnSystem) Update(w *lib.World, _ float64) {
+	input := s.input.Current()
+	if input.Pressed&ButtonLeft == 0 {
+		return
+	}
+	width, height := s.screen.RenderSize()
+	scale := float64(s.screen.ScaleDPI())
+	for i := 0; i < 3; i++ {
+		w.AddComponents(w.CreateEntity(),
+			Position{X: min(input.MouseX, float64(width)) * scale, Y: min(input.MouseY, float64(height)) * scale},
+			Velocity{X: s.rng.Float64()*2 - 1, Y: s.rng.Float64()*2 - 1},
+		)
+	}
+}
+
+type attractSystem struct {
+	input *InputSystem
+}
+
+func (s *attractSystem) Update(w *lib.World, deltaTime float64) {
+	input := s.input.Current()
+	for entity, row := range w.Query().With(positionID, velocityID).All() {
+		position := lib.Component[Position](row)
+		velocity := lib.Component[Velocity](row)
+		if input.Down&ButtonRight != 0 {
+			velocity.X += (input.MouseX - position.X) * deltaTime
+			velocity.Y += (input.MouseY - position.Y) * deltaTime
+		}
+		position.X += velocity.X * deltaTime
+		position.Y += velocity.Y * deltaTime
+		w.AddComponents(entity, position, velocity)
+	}
+}
+
+// scriptedSource stands in for a player moving the mouse around.
+type scriptedSource struct {
+	rng *rand.Rand
+}
+
+func (s *scriptedSource) Poll() Input {
+	input := Input{MouseX: s.rng.Float64() * 800, MouseY: s.rng.Float64() * 600}
+	if s.rng.Intn(10) == 0 {
+		input.Pressed |= ButtonLeft
+		input.Down |= ButtonLeft
+	}
+	if s.rng.Intn(3) == 0 {
+		input.Down |= ButtonRight
+	}
+	return input
+}
+
+func newSession(source Source, screen ScreenSource, seed int64, step float64) *lib.Loop {
+	world := lib.NewWorld()
+	input := NewInputSystem(source)
+	world.AddSystem(input)
+	world.AddSystem(&spawnSystem{input: input, screen: screen, rng: rand.New(rand.NewSource(seed))})
+	world.AddSystem(&attractSystem{input: input})
+	return lib.NewLoop(world, step)
+}
+
+func TestReplay_ReproducesSession(t *testing.T) {
+	const seed, step = 42, 1.0 / 60
+
+	// The window is resized and moved to a HiDPI display between frames.
+	screen := &Screen{Width: 800, Height: 600, DPI: 1}
+	recorder := NewRecorder(&scriptedSource{rng: rand.New(rand.NewSource(7))}, screen, seed, step)
+	live := newSession(recorder, screen, seed, step)
+	frameTimes := rand.New(rand.NewSource(11))
+	for live.Ticks() < 300 {
+		live.Advance(frameTimes.Float64() * step * 2)
+		switch live.Ticks() / 100 {
+		case 1:
+			screen.Width, screen.Height = 400, 300
+		case 2:
+			screen.DPI = 2
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	if err := recorder.Finish(live.World()).SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+	recording, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recording.Inputs) != int(live.Ticks()) {
+		t.Fatalf("expected %d recorded ticks, got %d", live.Ticks(), len(recording.Inputs))
+	}
+	if len(recording.Screens) != 3 {
+		t.Fatalf("expected 3 recorded screens, got %+v", recording.Screens)
+	}
+
+	player := NewPlayer(recording)
+	replayed := newSession(player, player, recording.Seed, recording.Step)
+	if err := Replay(replayed, player); err != nil {
+		t.Fatal(err)
+	}
+
+	if replayed.Ticks() != live.Ticks() {
+		t.Fatalf("expected %d ticks, got %d", live.Ticks(), replayed.Ticks())
+	}
+	if live.World().GetEntityCount() == 0 {
+		t.Fatal("expected the session to spawn entities")
+	}
+	if live.World().Hash() != replayed.World().Hash() {
+		t.Errorf("replayed world diverged from the recorded session:\n%s", lib.Diff(live.World(), replayed.World()))
+	}
+}
+
+func TestReplay_HashMismatch(t *testing.T) {
+	const seed, step = 42, 1.0 / 60
+
+	screen := &Screen{Width: 800, Height: 600, DPI: 1}
+	recorder := NewRecorder(&scriptedSource{rng: rand.New(rand.NewSource(7))}, screen, seed, step)
+	live := newSession(recorder, screen, seed, step)
+	for live.Ticks() < 60 {
+		live.Advance(step)
+	}
+	recording := recorder.Finish(live.World())
+
+	// A different seed spawns the same entities with different velocities.
+	player := NewPlayer(recording)
+	replayed := newSession(player, player, seed+1, recording.Step)
+	if err := Replay(replayed, player); err == nil {
+		t.Error("expected an error when the replayed world ends in another state")
+	}
+}
+
+func TestReplay_WithoutInputSystem(t *testing.T) {
+	recording := &Recording{Step: 1, Inputs: make([]Input, 3), Screens: []ScreenChange{{Screen: Screen{Width: 1, Height: 1, DPI: 1}}}}
+	loop := lib.NewLoop(lib.NewWorld(), recording.Step)
+	if err := Replay(loop, NewPlayer(recording)); err == nil {
+		t.Error("expected an error when nothing reads from the player")
+	}
+	if loop.Ticks() != 3 {
+		t.Errorf("expected replay to stop after 3 ticks, got %d", loop.Ticks())
+	}
+}
+
+func TestReplay_StepMismatch(t *testing.T) {
+	recording := &Recording{Step: 1.0 / 60, Inputs: make([]Input, 3), Screens: []ScreenChange{{Screen: Screen{Width: 1, Height: 1, DPI: 1}}}}
+	player := NewPlayer(recording)
+	loop := newSession(player, player, recording.Seed, 1.0/30)
+	if err := Replay(loop, player); err == nil {
+		t.Error("expected an error for a loop with a different step")
+	}
+	if loop.Ticks() != 0 {
+		t.Errorf("expected no ticks to run, got %d", loop.Ticks())
+	}
+}
+
+func TestPlayer_ExhaustedRecording(t *testing.T) {
+	player := NewPlayer(&Recording{Step: 1, Inputs: []Input{
+		{MouseX: 1, MouseY: 2, Down: ButtonLeft, Pressed: ButtonLeft},
+	}})
+
+	if input := player.Poll(); input.Pressed != ButtonLeft {
+		t.Errorf("expected recorded press, got %+v", input)
+	}
+	if !player.Done() {
+		t.Error("expected player to be done")
+	}
+	if input := player.Poll(); input != (Input{MouseX: 1, MouseY: 2}) {
+		t.Errorf("expected last mouse position without buttons, got %+v", input)
+	}
+}
+
+func TestPlayer_Screen(t *testing.T) {
+	player := NewPlayer(&Recording{Step: 1, Inputs: make([]Input, 3), Screens: []ScreenChange{
+		{Tick: 0, Screen: Screen{Width: 800, Height: 450, DPI: 1}},
+		{Tick: 2, Screen: Screen{Width: 1600, Height: 900, DPI: 2}},
+	}})
+
+	for tick, expected := range []Screen{{800, 450, 1}, {800, 450, 1}, {1600, 900, 2}, {1600, 900, 2}} {
+		player.Poll()
+		width, height := player.RenderSize()
+		if got := (Screen{Width: width, Height: height, DPI: player.ScaleDPI()}); got != expected {
+			t.Errorf("tick %d: expected screen %+v, got %+v", tick, expected, got)
+		}
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"zero step":         `{"seed":1,"step":0,"inputs":[],"screens":[{"tick":0,"width":800,"height":450,"dpi":1}]}`,
+		"no screen":         `{"seed":1,"step":1,"inputs":[]}`,
+		"late first screen": `{"seed":1,"step":1,"inputs":[],"screens":[{"tick":1,"width":800,"height":450,"dpi":1}]}`,
+		"out of order": `{"seed":1,"step":1,"inputs":[],"screens":[{"tick":0,"width":800,"height":450,"dpi":1},` +
+			`{"tick":0,"width":400,"height":450,"dpi":1}]}`,
+		"zero dpi": `{"seed":1,"step":1,"inputs":[],"screens":[{"tick":0,"width":800,"height":450,"dpi":0}]}`,
+	} {
+		if _, err := Load(bytes.NewBufferString(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
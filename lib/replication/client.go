@@ -0,0 +1,159 @@
+package replication
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"ecs/lib"
+)
+
+// Client applies a Server's packets to a local World.
+type Client struct {
+	world    *lib.World
+	entities map[lib.EntityID]lib.EntityID
+	states   map[uint64]state
+	tick     uint64
+}
+
+func NewClient(world *lib.World) *Client {
+	return &Client{
+		world:    world,
+		entities: make(map[lib.EntityID]lib.EntityID),
+		states:   map[uint64]state{0: {}},
+	}
+}
+
+// Apply rebuilds the server state at packet.Tick from the packet's baseline
+// and brings the World in line with it. Packets no newer than the last one
+// applied are ignored.
+func (c *Client) Apply(packet Packet) error {
+	if packet.Tick <= c.tick {
+		return nil
+	}
+	base, exists := c.states[packet.Baseline]
+	if !exists {
+		return fmt.Errorf("replication: packet for tick %d is based on unknown tick %d", packet.Tick, packet.Baseline)
+	}
+	next := base.apply(packet)
+	if err := c.sync(delta(c.states[c.tick], next), next); err != nil {
+		return err
+	}
+
+	c.states[packet.Tick] = next
+	c.tick = packet.Tick
+	// The server only ever moves its baseline forward.
+	for tick := range c.states {
+		if tick != 0 && tick < packet.Baseline {
+			delete(c.states, tick)
+		}
+	}
+	return nil
+}
+
+// Run applies packets from conn, acknowledging each, until the server closes
+// the connection. The World must not be touched elsewhere while it runs.
+func (c *Client) Run(conn net.Conn) error {
+	decoder := json.NewDecoder(conn)
+	encoder := json.NewEncoder(conn)
+	for {
+		var packet Packet
+		if err := decoder.Decode(&packet); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if err := c.Apply(packet); err != nil {
+			return err
+		}
+		if err := encoder.Encode(ackMessage{Tick: c.tick}); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) Tick() uint64 {
+	return c.tick
+}
+
+// Entity maps a server entity to its copy in the client World.
+func (c *Client) Entity(remote lib.EntityID) (lib.EntityID, bool) {
+	local, exists := c.entities[remote]
+	return local, exists
+}
+
+// entityUpdate is one EntityDelta decoded and resolved against the registry.
+type entityUpdate struct {
+	entity     lib.EntityID
+	components []interface{}
+	removed    []lib.ComponentID
+}
+
+// sync applies the delta from the current state to next onto the World. It
+// decodes the whole packet before touching the World, and if a change still
+// fails it destroys the entities created for this packet, so a failed packet
+// leaves nothing behind to be orphaned when it is sent again.
+func (c *Client) sync(packet Packet, next state) error {
+	updates := make([]entityUpdate, 0, len(packet.Created)+len(packet.Changed))
+	for _, change := range append(packet.Created, packet.Changed...) {
+		update := entityUpdate{entity: change.Entity}
+		for name := range change.Components {
+			component, err := decode(name, next[change.Entity][name])
+			if err != nil {
+				return err
+			}
+			update.components = append(update.components, component)
+		}
+		for _, name := range change.Removed {
+			componentID, err := lib.GetComponentIDByName(name)
+			if err != nil {
+				return err
+			}
+			update.removed = append(update.removed, componentID)
+		}
+		updates = append(updates, update)
+	}
+
+	created := make([]lib.EntityID, 0, len(packet.Created))
+	for _, change := range packet.Created {
+		c.entities[change.Entity] = c.world.CreateEntity()
+		created = append(created, change.Entity)
+	}
+	for _, update := range updates {
+		if err := c.update(update); err != nil {
+			for _, remote := range created {
+				c.world.DestroyEntity(c.entities[remote])
+				delete(c.entities, remote)
+			}
+			return err
+		}
+	}
+	for _, entity := range packet.Destroyed {
+		local, exists := c.entities[entity]
+		if !exists {
+			continue
+		}
+		c.world.DestroyEntity(local)
+		delete(c.entities, entity)
+	}
+	return nil
+}
+
+func (c *Client) update(update entityUpdate) error {
+	local, exists := c.entities[update.entity]
+	if !exists {
+		return fmt.Errorf("replication: change for unknown entity %d", update.entity)
+	}
+	if err := c.world.TryAddComponents(local, update.components...); err != nil {
+		return err
+	}
+	for _, componentID := range update.removed {
+		if err := c.world.TryRemoveComponent(local, componentID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
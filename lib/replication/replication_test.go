@@ -0,0 +1,341 @@
+package replication
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"ecs/lib"
+)
+
+type Position struct {
+	X, Y float64
+}
+
+type Health int
+
+type Frozen struct{}
+
+type Shield struct {
+	HP int `json:"hp,omitempty"`
+}
+
+type Inventory map[string]int
+
+type Secret struct {
+	Code string
+}
+
+var positionID = lib.RegisterComponent[Position](lib.Replicated())
+var healthID = lib.RegisterComponent[Health](lib.Replicated())
+var frozenID = lib.RegisterComponent[Frozen](lib.Replicated())
+var secretID = lib.RegisterComponent[Secret]()
+var shieldID = lib.RegisterComponent[Shield](lib.Replicated())
+var inventoryID = lib.RegisterComponent[Inventory](lib.Replicated())
+
+// gameplaySystem churns through every kind of change replication handles.
+type gameplaySystem struct {
+	tick int
+}
+
+func (s *gameplaySystem) Update(w *lib.World, _ float64) {
+	s.tick++
+	if s.tick%3 == 0 {
+		w.AddComponents(w.CreateEntity(), Position{X: float64(s.tick)}, Health(100), Secret{Code: "hidden"})
+	}
+	// Entities are added and destroyed below, so collect before changing any.
+	entities := make([]lib.EntityID, 0)
+	for entity := range w.Query().With(positionID).Entities() {
+		entities = append(entities, entity)
+	}
+	for _, entity := range entities {
+		position, _ := lib.Get[Position](w, entity)
+		position.Y += 1
+		w.AddComponents(entity, position)
+		switch (int(entity) + s.tick) % 7 {
+		case 0:
+			w.AddComponents(entity, Frozen{})
+		case 1:
+			if w.HasComponent(entity, frozenID) {
+				w.RemoveComponent(entity, frozenID)
+			}
+		case 2:
+			if s.tick%4 == 0 {
+				w.DestroyEntity(entity)
+			}
+		}
+	}
+}
+
+func replicatedComponents(w *lib.World, entity lib.EntityID) map[lib.ComponentID]interface{} {
+	components := make(map[lib.ComponentID]interface{})
+	for _, componentID := range w.ComponentsOf(entity) {
+		if lib.Registry.IsReplicated(componentID) {
+			components[componentID], _ = w.GetComponent(entity, componentID)
+		}
+	}
+	return components
+}
+
+func TestReplication_OverPipe(t *testing.T) {
+	serverWorld, clientWorld := lib.NewWorld(), lib.NewWorld()
+	server := NewServer(serverWorld)
+	serverWorld.AddSystem(&gameplaySystem{})
+	serverWorld.AddSystem(server)
+	client := NewClient(clientWorld)
+
+	serverConn, clientConn := net.Pipe()
+	peer := server.Accept(serverConn)
+	done := make(chan error, 1)
+	go func() {
+		done <- client.Run(clientConn)
+		clientConn.Close()
+	}()
+
+	for i := 0; i < 40; i++ {
+		serverWorld.Update(1.0 / 60)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for peer.Ack() != server.Tick() {
+		select {
+		case err := <-done:
+			t.Fatalf("client stopped at tick %d of %d: %v", peer.Ack(), server.Tick(), err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("client stuck at tick %d of %d", peer.Ack(), server.Tick())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	serverConn.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	replicated := 0
+	for entity := range serverWorld.Query().With(positionID).Entities() {
+		replicated++
+		local, exists := client.Entity(entity)
+		if !exists {
+			t.Fatalf("entity %d was not replicated", entity)
+		}
+		expected, got := replicatedComponents(serverWorld, entity), replicatedComponents(clientWorld, local)
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("entity %d: expected %v, got %v", entity, expected, got)
+		}
+		if clientWorld.HasComponent(local, secretID) {
+			t.Errorf("entity %d: unreplicated component was sent", entity)
+		}
+	}
+	if replicated == 0 || clientWorld.GetEntityCount() != replicated {
+		t.Errorf("expected %d client entities, got %d", replicated, clientWorld.GetEntityCount())
+	}
+}
+
+func TestServer_DeltaOnlyHasChangedFields(t *testing.T) {
+	w := lib.NewWorld()
+	server := NewServer(w)
+	moved, still := w.CreateEntity(), w.CreateEntity()
+	w.AddComponents(moved, Position{X: 1, Y: 2}, Health(10))
+	w.AddComponents(still, Position{X: 3, Y: 4})
+	server.Capture()
+
+	w.AddComponents(moved, Position{X: 5, Y: 2})
+	w.DisableComponent(moved, healthID)
+	w.DestroyEntity(still)
+	server.Capture()
+
+	expected := Packet{
+		Tick:     2,
+		Baseline: 1,
+		Changed: []EntityDelta{{
+			Entity:     moved,
+			Components: map[string]Fields{"replication.Position": {"X": json.RawMessage("5")}},
+			Removed:    []string{"replication.Health"},
+		}},
+		Destroyed: []lib.EntityID{still},
+	}
+	if packet := server.Delta(1); !reflect.DeepEqual(packet, expected) {
+		t.Errorf("expected %+v, got %+v", expected, packet)
+	}
+	if packet := server.Delta(99); packet.Baseline != 0 || len(packet.Created) != 1 {
+		t.Errorf("expected full state for an unknown ack, got %+v", packet)
+	}
+}
+
+func TestClient_ApplyAgainstOlderBaseline(t *testing.T) {
+	serverWorld, clientWorld := lib.NewWorld(), lib.NewWorld()
+	server := NewServer(serverWorld)
+	client := NewClient(clientWorld)
+	entity := serverWorld.CreateEntity()
+
+	serverWorld.AddComponents(entity, Position{X: 1}, Health(3))
+	server.Capture()
+	if err := client.Apply(server.Delta(0)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ticks 2 and 3 are both built against tick 1, as if acks were in flight.
+	serverWorld.AddComponents(entity, Position{X: 2})
+	server.Capture()
+	serverWorld.AddComponents(entity, Health(4), Frozen{})
+	server.Capture()
+	if err := client.Apply(server.Delta(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	local, _ := client.Entity(entity)
+	expected := map[lib.ComponentID]interface{}{positionID: Position{X: 2}, healthID: Health(4), frozenID: Frozen{}}
+	if got := replicatedComponents(clientWorld, local); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if err := client.Apply(Packet{Tick: 9, Baseline: 7}); err == nil {
+		t.Error("expected error for a packet based on an unknown tick")
+	}
+}
+
+func TestServer_StalledPeerDoesNotBlockTick(t *testing.T) {
+	serverWorld := lib.NewWorld()
+	server := NewServer(serverWorld)
+	server.SetWriteTimeout(20 * time.Millisecond)
+	serverWorld.AddSystem(&gameplaySystem{})
+	serverWorld.AddSystem(server)
+
+	// Nobody ever reads from the other end of this pipe.
+	stalledConn, _ := net.Pipe()
+	stalled := server.Accept(stalledConn)
+	serverConn, clientConn := net.Pipe()
+	healthy := server.Accept(serverConn)
+	done := make(chan error, 1)
+	go func() { done <- NewClient(lib.NewWorld()).Run(clientConn) }()
+
+	ticked := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			serverWorld.Update(1.0 / 60)
+		}
+		close(ticked)
+	}()
+	select {
+	case <-ticked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server tick blocked on a stalled peer")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for stalled.Err() == nil || healthy.Ack() != server.Tick() {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the stalled peer to time out (%v) and the healthy one to catch up (%d of %d)",
+				stalled.Err(), healthy.Ack(), server.Tick())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	serverWorld.Update(1.0 / 60)
+	if len(server.peers) != 1 || server.peers[0] != healthy {
+		t.Errorf("expected only the healthy peer to remain, got %d peers", len(server.peers))
+	}
+
+	serverConn.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_CorruptPacketLeavesWorldUntouched(t *testing.T) {
+	serverWorld, clientWorld := lib.NewWorld(), lib.NewWorld()
+	server := NewServer(serverWorld)
+	client := NewClient(clientWorld)
+	first, second := serverWorld.CreateEntity(), serverWorld.CreateEntity()
+	serverWorld.AddComponents(first, Position{X: 1})
+	serverWorld.AddComponents(second, Position{X: 2}, Health(5))
+	server.Capture()
+
+	corrupt := server.Delta(0)
+	corrupt.Created = append([]EntityDelta(nil), corrupt.Created...)
+	corrupt.Created[1] = EntityDelta{
+		Entity:     second,
+		Components: map[string]Fields{"replication.Position": {"X": json.RawMessage(`"two"`)}},
+	}
+	if err := client.Apply(corrupt); err == nil {
+		t.Fatal("expected an error for a packet that doesn't decode")
+	}
+	if client.Tick() != 0 {
+		t.Errorf("expected the tick to stay at 0, got %d", client.Tick())
+	}
+	for range clientWorld.Query().Entities() {
+		t.Fatal("expected no entities after a failed packet")
+	}
+
+	if err := client.Apply(server.Delta(0)); err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for range clientWorld.Query().Entities() {
+		count++
+	}
+	if count != 2 {
+		t.Errorf("expected 2 entities, got %d", count)
+	}
+	for remote, expected := range map[lib.EntityID]map[lib.ComponentID]interface{}{
+		first:  {positionID: Position{X: 1}},
+		second: {positionID: Position{X: 2}, healthID: Health(5)},
+	} {
+		local, _ := client.Entity(remote)
+		if got := replicatedComponents(clientWorld, local); !reflect.DeepEqual(got, expected) {
+			t.Errorf("entity %v: expected %v, got %v", remote, expected, got)
+		}
+	}
+}
+
+func TestClient_ApplyRemovedFields(t *testing.T) {
+	serverWorld, clientWorld := lib.NewWorld(), lib.NewWorld()
+	server := NewServer(serverWorld)
+	client := NewClient(clientWorld)
+	entity := serverWorld.CreateEntity()
+
+	serverWorld.AddComponents(entity, Shield{HP: 5}, Inventory{"a": 1, "b": 2})
+	server.Capture()
+	if err := client.Apply(server.Delta(0)); err != nil {
+		t.Fatal(err)
+	}
+
+	serverWorld.AddComponents(entity, Shield{HP: 0}, Inventory{"a": 1})
+	server.Capture()
+	packet := server.Delta(1)
+	expectedRemoved := map[string][]string{"replication.Shield": {"hp"}, "replication.Inventory": {"b"}}
+	if len(packet.Changed) != 1 || !reflect.DeepEqual(packet.Changed[0].RemovedFields, expectedRemoved) {
+		t.Fatalf("expected removed fields %v, got %+v", expectedRemoved, packet)
+	}
+	if err := client.Apply(packet); err != nil {
+		t.Fatal(err)
+	}
+
+	local, _ := client.Entity(entity)
+	expected := map[lib.ComponentID]interface{}{shieldID: Shield{}, inventoryID: Inventory{"a": 1}}
+	if got := replicatedComponents(clientWorld, local); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestClient_DestroyUnknownEntity(t *testing.T) {
+	serverWorld, clientWorld := lib.NewWorld(), lib.NewWorld()
+	server := NewServer(serverWorld)
+	client := NewClient(clientWorld)
+	entity := serverWorld.CreateEntity()
+	serverWorld.AddComponents(entity, Position{X: 1})
+	server.Capture()
+	if err := client.Apply(server.Delta(0)); err != nil {
+		t.Fatal(err)
+	}
+
+	local, _ := client.Entity(entity)
+	unknown := entity + 1
+	if err := client.sync(Packet{Destroyed: []lib.EntityID{unknown, unknown}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !clientWorld.HasEntity(local) {
+		t.Errorf("expected a delete for an unknown entity to leave local entity %v alone", local)
+	}
+}
@@ -0,0 +1,195 @@
+package replication
+
+import (
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"ecs/lib"
+)
+
+// HistorySize is how many past ticks a Server keeps to build deltas from. A
+// client whose last ack is older gets the full state again.
+const HistorySize = 64
+
+// DefaultWriteTimeout is how long a packet may take to reach a peer before
+// the peer is dropped.
+const DefaultWriteTimeout = 5 * time.Second
+
+// Server captures a World's replicated state every tick and sends deltas to
+// its peers. It is a lib.System; add it after the systems that change
+// replicated components.
+type Server struct {
+	world        *lib.World
+	tick         uint64
+	history      map[uint64]state
+	peers        []*Peer
+	writeTimeout time.Duration
+}
+
+// Peer is one client connection. Packets are written by the peer's own
+// goroutine so a slow client never holds up the server's tick; while a write
+// is in flight only the newest packet is kept, as each delta starts from the
+// client's last ack and so supersedes any unsent one.
+type Peer struct {
+	conn         net.Conn
+	outbox       chan Packet
+	done         chan struct{}
+	writeTimeout time.Duration
+
+	mu  sync.Mutex
+	ack uint64
+	err error
+}
+
+type ackMessage struct {
+	Tick uint64 `json:"ack"`
+}
+
+func NewServer(world *lib.World) *Server {
+	return &Server{
+		world:        world,
+		history:      make(map[uint64]state),
+		writeTimeout: DefaultWriteTimeout,
+	}
+}
+
+// SetWriteTimeout changes the write timeout of peers accepted from now on.
+func (s *Server) SetWriteTimeout(timeout time.Duration) {
+	s.writeTimeout = timeout
+}
+
+// Accept starts replicating to the client on the other end of conn.
+func (s *Server) Accept(conn net.Conn) *Peer {
+	peer := &Peer{
+		conn:         conn,
+		outbox:       make(chan Packet, 1),
+		done:         make(chan struct{}),
+		writeTimeout: s.writeTimeout,
+	}
+	s.peers = append(s.peers, peer)
+	go peer.readAcks()
+	go peer.writePackets()
+	return peer
+}
+
+// Update captures the tick and queues each peer its delta without waiting
+// for it to be written. Peers whose connection failed are dropped; Peer.Err
+// reports why.
+func (s *Server) Update(_ *lib.World, _ float64) {
+	if _, err := s.Capture(); err != nil {
+		for _, peer := range s.peers {
+			peer.fail(err)
+		}
+		s.peers = nil
+		return
+	}
+	peers := s.peers[:0]
+	for _, peer := range s.peers {
+		if peer.Err() != nil {
+			continue
+		}
+		peer.send(s.Delta(peer.Ack()))
+		peers = append(peers, peer)
+	}
+	s.peers = peers
+}
+
+// Capture records the World's replicated state as the next tick.
+func (s *Server) Capture() (uint64, error) {
+	current, err := capture(s.world)
+	if err != nil {
+		return s.tick, err
+	}
+	s.tick++
+	s.history[s.tick] = current
+	delete(s.history, s.tick-HistorySize)
+	return s.tick, nil
+}
+
+// Delta builds the packet that brings a client from its acknowledged tick to
+// the latest captured one.
+func (s *Server) Delta(ack uint64) Packet {
+	base, exists := s.history[ack]
+	if !exists {
+		ack, base = 0, nil
+	}
+	packet := delta(base, s.history[s.tick])
+	packet.Tick, packet.Baseline = s.tick, ack
+	return packet
+}
+
+func (s *Server) Tick() uint64 {
+	return s.tick
+}
+
+func (p *Peer) Ack() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.ack
+}
+
+func (p *Peer) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// send queues a packet, replacing one still waiting to be written.
+func (p *Peer) send(packet Packet) {
+	for {
+		select {
+		case p.outbox <- packet:
+			return
+		default:
+		}
+		select {
+		case <-p.outbox:
+		default:
+		}
+	}
+}
+
+func (p *Peer) writePackets() {
+	encoder := json.NewEncoder(p.conn)
+	for {
+		select {
+		case <-p.done:
+			return
+		case packet := <-p.outbox:
+			if err := p.conn.SetWriteDeadline(time.Now().Add(p.writeTimeout)); err != nil {
+				p.fail(err)
+				return
+			}
+			if err := encoder.Encode(packet); err != nil {
+				p.fail(err)
+				return
+			}
+		}
+	}
+}
+
+func (p *Peer) readAcks() {
+	decoder := json.NewDecoder(p.conn)
+	for {
+		var message ackMessage
+		if err := decoder.Decode(&message); err != nil {
+			p.fail(err)
+			return
+		}
+		p.mu.Lock()
+		p.ack = max(p.ack, message.Tick)
+		p.mu.Unlock()
+	}
+}
+
+func (p *Peer) fail(err error) {
+	p.mu.Lock()
+	if p.err == nil {
+		p.err = err
+		close(p.done)
+	}
+	p.mu.Unlock()
+	p.conn.Close()
+}
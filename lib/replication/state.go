@@ -0,0 +1,213 @@
+// Package replication mirrors components registered with lib.Replicated from
+// a server World into client Worlds.
+//
+// Every tick the Server captures the replicated state and sends each client a
+// Packet holding only what changed since the tick that client last
+// acknowledged. Components travel as JSON, so only exported fields are
+// replicated, and disabled components count as absent. Entity IDs in packets
+// are the server's; Client.Entity maps them to the client's own.
+package replication
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"ecs/lib"
+)
+
+// Packet is the delta from Baseline to Tick. A zero Baseline means the delta
+// is from an empty world.
+type Packet struct {
+	Tick      uint64         `json:"tick"`
+	Baseline  uint64         `json:"baseline"`
+	Created   []EntityDelta  `json:"created,omitempty"`
+	Changed   []EntityDelta  `json:"changed,omitempty"`
+	Destroyed []lib.EntityID `json:"destroyed,omitempty"`
+}
+
+// EntityDelta carries the changed fields of each component, keyed by the
+// component's full type name, e.g. "game.Position". Components that are new
+// to the entity carry every field. RemovedFields lists, per component, the
+// fields that no longer appear in its encoding, such as omitempty fields that
+// became zero or deleted map keys; those components are also in Components,
+// even when none of their remaining fields changed.
+type EntityDelta struct {
+	Entity        lib.EntityID        `json:"entity"`
+	Components    map[string]Fields   `json:"components,omitempty"`
+	RemovedFields map[string][]string `json:"removedFields,omitempty"`
+	Removed       []string            `json:"removed,omitempty"`
+}
+
+// Fields maps field names to encoded values. Components that don't encode to
+// a JSON object are stored whole under the empty name.
+type Fields map[string]json.RawMessage
+
+type state map[lib.EntityID]map[string]Fields
+
+func capture(world *lib.World) (state, error) {
+	s := make(state)
+	for entity := range world.Query().Entities() {
+		for _, componentID := range world.ComponentsOf(entity) {
+			if !lib.Registry.IsReplicated(componentID) {
+				continue
+			}
+			component, enabled := world.GetComponent(entity, componentID)
+			if !enabled {
+				continue
+			}
+			fields, err := encode(component)
+			if err != nil {
+				return nil, err
+			}
+			if s[entity] == nil {
+				s[entity] = make(map[string]Fields)
+			}
+			s[entity][reflect.TypeOf(component).String()] = fields
+		}
+	}
+	return s, nil
+}
+
+func encode(component interface{}) (Fields, error) {
+	raw, err := json.Marshal(component)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %T: %w", component, err)
+	}
+	var fields Fields
+	if json.Unmarshal(raw, &fields) != nil {
+		return Fields{"": raw}, nil
+	}
+	return fields, nil
+}
+
+// decode builds a component value from a full set of fields.
+func decode(name string, fields Fields) (interface{}, error) {
+	componentID, err := lib.GetComponentIDByName(name)
+	if err != nil {
+		return nil, err
+	}
+	componentType, _ := lib.Registry.TypeOf(componentID)
+	raw, whole := fields[""]
+	if !whole {
+		if raw, err = json.Marshal(fields); err != nil {
+			return nil, err
+		}
+	}
+	value := reflect.New(componentType)
+	if err := json.Unmarshal(raw, value.Interface()); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", name, err)
+	}
+	return value.Elem().Interface(), nil
+}
+
+// delta lists what turns base into current, ordered by entity.
+func delta(base, current state) Packet {
+	var packet Packet
+	for _, entity := range slices.Sorted(maps.Keys(current)) {
+		components := current[entity]
+		baseComponents, existed := base[entity]
+		if !existed {
+			packet.Created = append(packet.Created, EntityDelta{Entity: entity, Components: components})
+			continue
+		}
+
+		change := EntityDelta{Entity: entity}
+		for name, fields := range components {
+			baseFields, had := baseComponents[name]
+			changed := changedFields(baseFields, fields)
+			removed := removedFields(baseFields, fields)
+			if had && len(changed) == 0 && len(removed) == 0 {
+				continue
+			}
+			if change.Components == nil {
+				change.Components = make(map[string]Fields)
+			}
+			change.Components[name] = changed
+			if len(removed) > 0 {
+				if change.RemovedFields == nil {
+					change.RemovedFields = make(map[string][]string)
+				}
+				change.RemovedFields[name] = removed
+			}
+		}
+		for name := range baseComponents {
+			if _, exists := components[name]; !exists {
+				change.Removed = append(change.Removed, name)
+			}
+		}
+		slices.Sort(change.Removed)
+		if change.Components != nil || change.Removed != nil {
+			packet.Changed = append(packet.Changed, change)
+		}
+	}
+	for _, entity := range slices.Sorted(maps.Keys(base)) {
+		if _, exists := current[entity]; !exists {
+			packet.Destroyed = append(packet.Destroyed, entity)
+		}
+	}
+	return packet
+}
+
+// changedFields returns the fields of current that differ from base.
+func changedFields(base, current Fields) Fields {
+	changed := make(Fields)
+	for name, value := range current {
+		if !bytes.Equal(base[name], value) {
+			changed[name] = value
+		}
+	}
+	return changed
+}
+
+// removedFields returns the names of fields in base that current lacks,
+// sorted.
+func removedFields(base, current Fields) []string {
+	var removed []string
+	for name := range base {
+		if _, exists := current[name]; !exists {
+			removed = append(removed, name)
+		}
+	}
+	slices.Sort(removed)
+	return removed
+}
+
+// apply returns base with the packet applied, leaving base untouched.
+func (s state) apply(packet Packet) state {
+	result := make(state, len(s))
+	for entity, components := range s {
+		result[entity] = components
+	}
+	for _, created := range packet.Created {
+		result[created.Entity] = created.Components
+	}
+	for _, change := range packet.Changed {
+		components := maps.Clone(result[change.Entity])
+		if components == nil {
+			components = make(map[string]Fields)
+		}
+		for name, fields := range change.Components {
+			merged := maps.Clone(components[name])
+			if merged == nil {
+				merged = make(Fields)
+			}
+			maps.Copy(merged, fields)
+			for _, field := range change.RemovedFields[name] {
+				delete(merged, field)
+			}
+			components[name] = merged
+		}
+		for _, name := range change.Removed {
+			delete(components, name)
+		}
+		result[change.Entity] = components
+	}
+	for _, entity := range packet.Destroyed {
+		delete(result, entity)
+	}
+	return result
+}
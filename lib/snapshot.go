@@ -0,0 +1,74 @@
+package lib
+
+// Snapshot is a frozen copy of a World's entities and components, for undo
+// and rollback. Taking one costs time in the number of archetypes and
+// columns, not entities, and so does restoring one into a World without
+// field or spatial indexes: all storage stays shared with the World and is
+// copied a page at a time as the World writes to it, so memory grows with
+// what changes after the snapshot rather than with the size of the world.
+//
+// Component values are copied shallowly: pointers, slices and maps held
+// inside a component are shared, as with CloneEntity without a clone hook.
+type Snapshot struct {
+	locations   pages[entityLocation]
+	freeIDs     pages[EntityID]
+	entityCount int
+	archetypes  map[Bitset]*Archetype
+	sparseSets  map[ComponentID]*sparseSet
+}
+
+func (w *World) Snapshot() *Snapshot {
+	snapshot := &Snapshot{
+		locations:   w.locations.share(),
+		freeIDs:     w.freeIDs.share(),
+		entityCount: w.entityCount,
+		archetypes:  make(map[Bitset]*Archetype, len(w.archetypes)),
+		sparseSets:  make(map[ComponentID]*sparseSet, len(w.sparseSets)),
+	}
+	for bitset, archetype := range w.archetypes {
+		snapshot.archetypes[bitset] = archetype.share()
+	}
+	for componentID, set := range w.sparseSets {
+		snapshot.sparseSets[componentID] = set.share()
+	}
+	return snapshot
+}
+
+// Restore puts the World back into the state it had when the snapshot was
+// taken, including which entity IDs come next. No OnRemove hooks run. The
+// snapshot stays valid and can be restored again. Indexes aren't part of the
+// snapshot, so each one is rebuilt by walking its component before and after
+// the rewind, which costs time in the number of entities that have it.
+func (w *World) Restore(snapshot *Snapshot) {
+	for componentID, indexes := range w.indexes {
+		for _, index := range indexes {
+			w.eachComponent(componentID, func(entity EntityID, _ interface{}) { index.unset(entity) })
+		}
+	}
+
+	w.locations = snapshot.locations.share()
+	w.freeIDs = snapshot.freeIDs.share()
+	w.entityCount = snapshot.entityCount
+
+	for bitset, frozen := range snapshot.archetypes {
+		archetype := w.archetypeFor(bitset, len(frozen.components))
+		*archetype = *frozen.share()
+	}
+	for bitset, archetype := range w.archetypes {
+		if _, exists := snapshot.archetypes[bitset]; !exists {
+			*archetype = *NewArchetype(bitset, 0, 0)
+		}
+	}
+
+	w.sparseSets = make(map[ComponentID]*sparseSet, len(snapshot.sparseSets))
+	for componentID, frozen := range snapshot.sparseSets {
+		w.sparseSets[componentID] = frozen.share()
+	}
+
+	w.queryCache.Clear()
+	for componentID, indexes := range w.indexes {
+		for _, index := range indexes {
+			w.eachComponent(componentID, index.set)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package lib
+
+import "testing"
+
+func TestWorld_SnapshotRestore(t *testing.T) {
+	build := func() *World {
+		w := NewWorld()
+		for i := 0; i < 5; i++ {
+			entity := w.CreateEntity()
+			w.AddComponents(entity, PositionComponent{x: float64(i)}, layerComponent{Layer: int32(i % 2)})
+			if i%2 == 0 {
+				w.AddComponents(entity, CharacterComponent{name: "npc"}, selectedComponent{Order: i})
+			}
+		}
+		w.DisableComponent(1, GetComponentID[PositionComponent]())
+		w.CreateEntity()
+		return w
+	}
+	w := build()
+	CreateIndex[layerComponent](w, "Layer", HashIndex)
+	expected := w.Hash()
+	snapshot := w.Snapshot()
+
+	mutations := []func(){
+		func() { w.AddComponents(0, PositionComponent{x: 100}) },
+		func() { w.AddComponents(2, selectedComponent{Order: 100}) },
+		func() { w.RemoveComponent(2, GetComponentID[CharacterComponent]()) },
+		func() { w.EnableComponent(1, GetComponentID[PositionComponent]()) },
+		func() { w.DisableComponent(4, selectedComponentID) },
+		func() { w.DestroyEntity(3) },
+		func() { w.AddComponents(w.CreateEntity(), handleComponent{}, layerComponent{Layer: 1}) },
+		func() { w.AddComponents(1, layerComponent{Layer: 0}) },
+	}
+	for round := 0; round < 2; round++ {
+		for _, mutate := range mutations {
+			mutate()
+		}
+		if w.Hash() == expected {
+			t.Fatal("expected mutations to change the world")
+		}
+
+		w.Restore(snapshot)
+		if w.Hash() != expected {
+			t.Fatalf("round %d: restored world differs:\n%s", round, Diff(build(), w))
+		}
+	}
+
+	if got := w.CreateEntity(); got != 6 {
+		t.Errorf("expected entity ids to resume from the snapshot, got %d", got)
+	}
+	if got := Lookup[layerComponent](w, "Layer", 1); len(got) != 2 || got[0] != 1 || got[1] != 3 {
+		t.Errorf("expected index to be rebuilt, got %v", got)
+	}
+	if got := w.Query().With(GetComponentID[CharacterComponent]()).Get().Entities; len(got) != 3 {
+		t.Errorf("expected query cache to be reset, got %v", got)
+	}
+}
+
+// copiedPages counts the pages the live side no longer shares with a frozen
+// copy.
+func copiedPages[T any](live, frozen *pages[T]) int {
+	copied := 0
+	for page := range frozen.table {
+		if page >= len(live.table) || &live.table[page][0] != &frozen.table[page][0] {
+			copied++
+		}
+	}
+	return copied
+}
+
+func TestWorld_SnapshotCopyOnWrite(t *testing.T) {
+	w := NewWorld()
+	for i := 0; i < 4*pageSize; i++ {
+		w.AddComponents(w.CreateEntity(), PositionComponent{x: float64(i)}, layerComponent{})
+	}
+	w.AddComponents(w.CreateEntity(), CharacterComponent{name: "npc"})
+	positionID := GetComponentID[PositionComponent]()
+	layerID := GetComponentID[layerComponent]()
+	bitset := w.locations.at(0).archetype
+	snapshot := w.Snapshot()
+
+	w.AddComponents(5, PositionComponent{x: 100})
+	w.RemoveComponent(2*pageSize+1, layerID)
+
+	live, frozen := w.archetypes[bitset], snapshot.archetypes[bitset]
+	// Page 0 holds the update; the removal copies the last row into page 2
+	// and only truncates page 3.
+	if copied := copiedPages(live.components[positionID], frozen.components[positionID]); copied != 2 {
+		t.Errorf("expected 2 of 4 position pages to be copied, got %d", copied)
+	}
+	if copied := copiedPages(live.components[layerID], frozen.components[layerID]); copied != 1 {
+		t.Errorf("expected 1 of 4 layer pages to be copied, got %d", copied)
+	}
+	if copied := copiedPages(&w.locations, &snapshot.locations); copied != 2 {
+		t.Errorf("expected only the moved entities' location pages to be copied, got %d", copied)
+	}
+	other := w.archetypes[w.locations.at(4*pageSize).archetype]
+	if copied := copiedPages(&other.entities, &snapshot.archetypes[other.bitset].entities); copied != 0 {
+		t.Error("expected untouched archetype to stay shared")
+	}
+	if position := frozen.value(positionID, snapshot.locations.at(5).row).(PositionComponent); position.x != 5 {
+		t.Errorf("expected snapshot to keep the old value, got %+v", position)
+	}
+}
+
+func TestWorld_RestoreRewindsFreeIDs(t *testing.T) {
+	w := NewWorld()
+	a, b := w.CreateEntity(), w.CreateEntity()
+	w.DestroyEntity(a)
+	snapshot := w.Snapshot()
+
+	if reused := w.CreateEntity(); reused != a {
+		t.Fatalf("expected destroyed id %v to be reused, got %v", a, reused)
+	}
+	w.DestroyEntity(b)
+	w.Restore(snapshot)
+	if next := w.CreateEntity(); next != a {
+		t.Errorf("expected Restore to hand out %v next, got %v", a, next)
+	}
+	if !w.HasEntity(b) {
+		t.Errorf("expected %v to be restored", b)
+	}
+}
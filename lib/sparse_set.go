@@ -0,0 +1,133 @@
+package lib
+
+type StorageKind int
+
+const (
+	// TableStorage keeps the component in archetype columns. Adding or
+	// removing it moves the entity to another archetype.
+	TableStorage StorageKind = iota
+	// SparseSetStorage keeps the component outside archetype identity, so
+	// toggling it never moves the entity. Reads cost a map lookup.
+	SparseSetStorage
+)
+
+type sparseSet struct {
+	entities pages[EntityID]
+	values   pages[interface{}]
+	disabled pages[bool]
+
+	// rows is indexed by EntityID and holds each entity's row plus one, so
+	// the zero value means absent.
+	rows pages[int]
+}
+
+func newSparseSet() *sparseSet {
+	return &sparseSet{}
+}
+
+func (s *sparseSet) len() int {
+	return s.entities.len()
+}
+
+func (s *sparseSet) row(entity EntityID) int {
+	if entity >= EntityID(s.rows.len()) {
+		return -1
+	}
+	return s.rows.at(int(entity)) - 1
+}
+
+func (s *sparseSet) get(entity EntityID) (interface{}, bool) {
+	row := s.row(entity)
+	if row == -1 {
+		return nil, false
+	}
+	return s.values.at(row), true
+}
+
+func (s *sparseSet) set(entity EntityID, component interface{}) {
+	if row := s.row(entity); row != -1 {
+		s.values.set(row, component)
+		return
+	}
+	s.rows.grow(int(entity) + 1)
+	s.rows.set(int(entity), s.entities.len()+1)
+	s.entities.append(entity)
+	s.values.append(component)
+	s.disabled.append(false)
+}
+
+func (s *sparseSet) remove(entity EntityID) {
+	row := s.row(entity)
+	if row == -1 {
+		return
+	}
+	lastIdx := s.entities.len() - 1
+	if row != lastIdx {
+		moved := s.entities.at(lastIdx)
+		s.entities.set(row, moved)
+		s.values.set(row, s.values.at(lastIdx))
+		s.disabled.set(row, s.disabled.at(lastIdx))
+		s.rows.set(int(moved), row+1)
+	}
+	s.rows.set(int(entity), 0)
+	s.entities.pop()
+	s.values.pop()
+	s.disabled.pop()
+}
+
+func (s *sparseSet) isDisabled(entity EntityID) bool {
+	row := s.row(entity)
+	return row != -1 && s.disabled.at(row)
+}
+
+func (s *sparseSet) setDisabled(entity EntityID, disabled bool) {
+	if row := s.row(entity); row != -1 && s.disabled.at(row) != disabled {
+		s.disabled.set(row, disabled)
+	}
+}
+
+// share hands out a frozen copy for a Snapshot, like Archetype.share.
+func (s *sparseSet) share() *sparseSet {
+	return &sparseSet{
+		entities: s.entities.share(),
+		values:   s.values.share(),
+		disabled: s.disabled.share(),
+		rows:     s.rows.share(),
+	}
+}
+
+func (w *World) sparseSet(componentID ComponentID) *sparseSet {
+	set, exists := w.sparseSets[componentID]
+	if !exists {
+		set = newSparseSet()
+		w.sparseSets[componentID] = set
+	}
+	return set
+}
+
+// sparseValue reads a sparse component whether or not it is disabled.
+func (w *World) sparseValue(entity EntityID, componentID ComponentID) (interface{}, bool) {
+	set, exists := w.sparseSets[componentID]
+	if !exists {
+		return nil, false
+	}
+	return set.get(entity)
+}
+
+// sparseMask reports which sparse components the entity has and which of
+// those are disabled.
+func (w *World) sparseMask(entity EntityID) (present Bitset, disabled Bitset) {
+	for _, componentID := range Registry.sparse.IDs() {
+		set, exists := w.sparseSets[componentID]
+		if !exists {
+			continue
+		}
+		if _, has := set.get(entity); has {
+			present = present.AddID(componentID)
+			if set.isDisabled(entity) {
+				disabled = disabled.AddID(componentID)
+			}
+		}
+	}
+	return present, disabled
+}
@@ -0,0 +1,148 @@
+package lib
+
+import (
+	"reflect"
+	"testing"
+)
+
+type selectedComponent struct {
+	Order int
+}
+
+var selectedComponentID = RegisterComponent[selectedComponent](WithStorage(SparseSetStorage))
+
+func TestSparseSet_TogglingDoesNotMoveEntity(t *testing.T) {
+	w := NewWorld()
+	entity := w.CreateEntity()
+	w.AddComponents(entity, PositionComponent{x: 1})
+	archetype := w.archetypes[w.locations.at(int(entity)).archetype]
+
+	w.AddComponents(entity, selectedComponent{Order: 1})
+	if w.archetypes[w.locations.at(int(entity)).archetype] != archetype {
+		t.Errorf("expected sparse component not to change the archetype")
+	}
+	if selected, ok := Get[selectedComponent](w, entity); !ok || selected.Order != 1 {
+		t.Errorf("expected selected component, got %v (%v)", selected, ok)
+	}
+	expected := Bitset(0).AddID(GetComponentID[PositionComponent]()).AddID(selectedComponentID).IDs()
+	if got := w.ComponentsOf(entity); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	w.RemoveComponent(entity, selectedComponentID)
+	if Has[selectedComponent](w, entity) || w.archetypes[w.locations.at(int(entity)).archetype] != archetype {
+		t.Errorf("expected sparse removal to leave the entity in place")
+	}
+	if err := w.TryRemoveComponent(entity, selectedComponentID); err == nil {
+		t.Errorf("expected removing a missing sparse component to fail")
+	}
+}
+
+func TestSparseSet_QueriesJoinStorageKinds(t *testing.T) {
+	w := NewWorld()
+	for i := 0; i < 10; i++ {
+		entity := w.CreateEntity()
+		if i%2 == 0 {
+			w.AddComponents(entity, PositionComponent{x: float64(i)})
+		}
+		if i%3 == 0 {
+			w.AddComponents(entity, selectedComponent{Order: i})
+		}
+	}
+	w.DisableComponent(6, selectedComponentID)
+
+	collect := func(q *Query) []EntityID {
+		got := make([]EntityID, 0)
+		q.Each(func(id EntityID, _ map[ComponentID]interface{}) { got = append(got, id) })
+		iterated := make([]EntityID, 0)
+		for entity := range q.Entities() {
+			iterated = append(iterated, entity)
+		}
+		if !reflect.DeepEqual(got, iterated) {
+			t.Errorf("expected Each %v and All %v to agree", got, iterated)
+		}
+		return got
+	}
+
+	positionID := GetComponentID[PositionComponent]()
+	tests := []struct {
+		name     string
+		query    *Query
+		expected []EntityID
+	}{
+		{"sparse only", w.Query().With(selectedComponentID), []EntityID{0, 3, 9}},
+		{"table and sparse", w.Query().With(positionID, selectedComponentID), []EntityID{0}},
+		{"table without sparse", w.Query().With(positionID).Without(selectedComponentID), []EntityID{2, 4, 8}},
+		{"sparse predicate", Where(w.Query(), func(s *selectedComponent) bool { return s.Order > 1 }), []EntityID{3, 9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := collect(tt.query); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSparseSet_LifecycleAndTransfer(t *testing.T) {
+	w := NewWorld()
+	entity := w.CreateEntity()
+	w.AddComponents(entity, selectedComponent{Order: 7}, CharacterComponent{name: "picked"})
+	w.DisableComponent(entity, selectedComponentID)
+	CreateIndex[selectedComponent](w, "Order", HashIndex)
+
+	clone := w.CloneEntity(entity)
+	if _, ok := w.componentValue(clone, selectedComponentID); !ok || !w.isDisabled(clone, selectedComponentID) {
+		t.Errorf("expected clone to carry the disabled sparse component")
+	}
+	if got := Lookup[selectedComponent](w, "Order", 7); !reflect.DeepEqual(got, []EntityID{entity, clone}) {
+		t.Errorf("expected index to see both entities, got %v", got)
+	}
+
+	other := NewWorld()
+	moved := w.MoveEntityTo(other, entity)
+	w.DestroyEntity(clone)
+	if w.sparseSets[selectedComponentID].len() != 0 {
+		t.Errorf("expected sparse set to be empty after move and destroy")
+	}
+	if got := Lookup[selectedComponent](w, "Order", 7); len(got) != 0 {
+		t.Errorf("expected index to be empty, got %v", got)
+	}
+
+	other.EnableComponent(moved, selectedComponentID)
+	if selected, ok := Get[selectedComponent](other, moved); !ok || selected.Order != 7 {
+		t.Errorf("expected moved sparse component, got %v (%v)", selected, ok)
+	}
+}
+
+func TestRegisterComponent_StorageKindIsFixed(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		id       ComponentID
+		register func()
+	}{
+		{"table to sparse", GetComponentID[PositionComponent](), func() {
+			RegisterComponent[PositionComponent](WithStorage(SparseSetStorage))
+		}},
+		{"sparse to table", selectedComponentID, func() {
+			RegisterComponent[selectedComponent](WithStorage(TableStorage))
+		}},
+	} {
+		sparse := Registry.isSparse(tc.id)
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected a panic", tc.name)
+				}
+			}()
+			tc.register()
+		}()
+		if Registry.isSparse(tc.id) != sparse || (Registry.info[tc.id].storage == SparseSetStorage) != sparse {
+			t.Errorf("%s: expected the storage kind to be left unchanged", tc.name)
+		}
+	}
+
+	if RegisterComponent[selectedComponent](WithStorage(SparseSetStorage)) != selectedComponentID || !Registry.isSparse(selectedComponentID) {
+		t.Errorf("expected re-registering with the same storage kind to be allowed")
+	}
+}
@@ -0,0 +1,261 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// SpatialIndex buckets entities into a uniform grid by the position read from
+// one component type. The World keeps it in sync as that component is added,
+// updated or removed.
+type SpatialIndex struct {
+	cellSize float64
+	position func(interface{}) (float64, float64)
+	cells    map[gridCell]map[EntityID]struct{}
+	points   map[EntityID]spatialPoint
+
+	// bounds covers every occupied cell. Removing a cell on its edge only
+	// marks it stale; queries shrink it again before searching.
+	bounds      cellBounds
+	boundsStale bool
+}
+
+type cellBounds struct {
+	min, max gridCell
+}
+
+type gridCell struct {
+	x, y int
+}
+
+type spatialPoint struct {
+	x, y float64
+	cell gridCell
+}
+
+// NewSpatialIndex panics unless cellSize is a positive, finite length.
+func NewSpatialIndex[T any](w *World, cellSize float64, position func(T) (x, y float64)) *SpatialIndex {
+	if !(cellSize > 0) || math.IsInf(cellSize, 1) {
+		panic(fmt.Sprintf("spatial index cell size must be positive and finite, got %v", cellSize))
+	}
+	componentID := GetComponentID[T]()
+	index := &SpatialIndex{
+		cellSize: cellSize,
+		position: func(component interface{}) (float64, float64) { return position(component.(T)) },
+		cells:    make(map[gridCell]map[EntityID]struct{}),
+		points:   make(map[EntityID]spatialPoint),
+	}
+	w.eachComponent(componentID, index.set)
+	w.indexes[componentID] = append(w.indexes[componentID], index)
+	return index
+}
+
+func (s *SpatialIndex) QueryRect(minX, minY, maxX, maxY float64) []EntityID {
+	return s.collect(minX, minY, maxX, maxY, func(p spatialPoint) bool {
+		return p.x >= minX && p.x <= maxX && p.y >= minY && p.y <= maxY
+	})
+}
+
+func (s *SpatialIndex) QueryRadius(x, y, radius float64) []EntityID {
+	return s.collect(x-radius, y-radius, x+radius, y+radius, func(p spatialPoint) bool {
+		return distanceSquared(p.x, p.y, x, y) <= radius*radius
+	})
+}
+
+// Nearest searches outwards ring by ring and stops once no unvisited cell
+// can hold anything closer than the best match so far. The search starts at
+// the first ring that reaches the occupied bounds, and only the perimeter of
+// each ring inside them is visited, so a query far from the data costs no
+// more than one next to it.
+func (s *SpatialIndex) Nearest(x, y float64) (EntityID, bool) {
+	if len(s.points) == 0 {
+		return 0, false
+	}
+	if s.boundsStale {
+		s.recomputeBounds()
+	}
+	center := s.cellOf(x, y)
+	minRing := max(
+		s.bounds.min.x-center.x, center.x-s.bounds.max.x,
+		s.bounds.min.y-center.y, center.y-s.bounds.max.y, 0,
+	)
+	maxRing := max(
+		abs(s.bounds.min.x-center.x), abs(s.bounds.max.x-center.x),
+		abs(s.bounds.min.y-center.y), abs(s.bounds.max.y-center.y),
+	)
+
+	var nearest EntityID
+	best := math.Inf(1)
+	visit := func(cx, cy int) {
+		for entity := range s.cells[gridCell{cx, cy}] {
+			p := s.points[entity]
+			d := distanceSquared(p.x, p.y, x, y)
+			if d < best || (d == best && entity < nearest) {
+				best, nearest = d, entity
+			}
+		}
+	}
+	for ring := minRing; ring <= maxRing; ring++ {
+		s.eachRingCell(center, ring, visit)
+		reach := float64(ring) * s.cellSize
+		if best <= reach*reach {
+			break
+		}
+	}
+	return nearest, true
+}
+
+// eachRingCell calls fn for the cells at Chebyshev distance ring from center,
+// skipping sides and stretches that fall outside the occupied bounds.
+func (s *SpatialIndex) eachRingCell(center gridCell, ring int, fn func(cx, cy int)) {
+	b := s.bounds
+	if ring == 0 {
+		fn(center.x, center.y)
+		return
+	}
+	fromX, toX := max(center.x-ring, b.min.x), min(center.x+ring, b.max.x)
+	for _, cy := range [2]int{center.y - ring, center.y + ring} {
+		if cy < b.min.y || cy > b.max.y {
+			continue
+		}
+		for cx := fromX; cx <= toX; cx++ {
+			fn(cx, cy)
+		}
+	}
+	fromY, toY := max(center.y-ring+1, b.min.y), min(center.y+ring-1, b.max.y)
+	for _, cx := range [2]int{center.x - ring, center.x + ring} {
+		if cx < b.min.x || cx > b.max.x {
+			continue
+		}
+		for cy := fromY; cy <= toY; cy++ {
+			fn(cx, cy)
+		}
+	}
+}
+
+func (s *SpatialIndex) recomputeBounds() {
+	first := true
+	for cell := range s.cells {
+		if first {
+			s.bounds = cellBounds{min: cell, max: cell}
+			first = false
+			continue
+		}
+		s.bounds.include(cell)
+	}
+	s.boundsStale = false
+}
+
+func (b *cellBounds) include(cell gridCell) {
+	b.min.x, b.min.y = min(b.min.x, cell.x), min(b.min.y, cell.y)
+	b.max.x, b.max.y = max(b.max.x, cell.x), max(b.max.y, cell.y)
+}
+
+func (b cellBounds) onEdge(cell gridCell) bool {
+	return cell.x == b.min.x || cell.x == b.max.x || cell.y == b.min.y || cell.y == b.max.y
+}
+
+func (s *SpatialIndex) set(entity EntityID, component interface{}) {
+	x, y := s.position(component)
+	cell := s.cellOf(x, y)
+	if old, exists := s.points[entity]; exists && old.cell != cell {
+		s.unset(entity)
+	}
+	s.points[entity] = spatialPoint{x: x, y: y, cell: cell}
+	entities, exists := s.cells[cell]
+	if !exists {
+		entities = make(map[EntityID]struct{})
+		if len(s.cells) == 0 {
+			s.bounds = cellBounds{min: cell, max: cell}
+			s.boundsStale = false
+		} else {
+			s.bounds.include(cell)
+		}
+		s.cells[cell] = entities
+	}
+	entities[entity] = struct{}{}
+}
+
+func (s *SpatialIndex) unset(entity EntityID) {
+	point, exists := s.points[entity]
+	if !exists {
+		return
+	}
+	delete(s.points, entity)
+	delete(s.cells[point.cell], entity)
+	if len(s.cells[point.cell]) == 0 {
+		delete(s.cells, point.cell)
+		if s.bounds.onEdge(point.cell) {
+			s.boundsStale = true
+		}
+	}
+}
+
+// collect visits the cells covering the given area, clamped to the occupied
+// bounds so the cost follows the data rather than the size of the query. When
+// that still spans more cells than are occupied, it walks the occupied cells
+// instead.
+func (s *SpatialIndex) collect(minX, minY, maxX, maxY float64, match func(spatialPoint) bool) []EntityID {
+	entities := make([]EntityID, 0)
+	if len(s.points) == 0 {
+		return entities
+	}
+	if s.boundsStale {
+		s.recomputeBounds()
+	}
+	fromX, toX, okX := s.clampSpan(minX, maxX, s.bounds.min.x, s.bounds.max.x)
+	fromY, toY, okY := s.clampSpan(minY, maxY, s.bounds.min.y, s.bounds.max.y)
+	if !okX || !okY {
+		return entities
+	}
+
+	add := func(cell map[EntityID]struct{}) {
+		for entity := range cell {
+			if match(s.points[entity]) {
+				entities = append(entities, entity)
+			}
+		}
+	}
+	if area := (toX - fromX + 1) * (toY - fromY + 1); area > len(s.cells) {
+		for cell, members := range s.cells {
+			if cell.x >= fromX && cell.x <= toX && cell.y >= fromY && cell.y <= toY {
+				add(members)
+			}
+		}
+	} else {
+		for cx := fromX; cx <= toX; cx++ {
+			for cy := fromY; cy <= toY; cy++ {
+				add(s.cells[gridCell{cx, cy}])
+			}
+		}
+	}
+	sort.Slice(entities, func(i, j int) bool { return entities[i] < entities[j] })
+	return entities
+}
+
+// clampSpan maps the coordinate span [from, to] to cell coordinates within
+// [lo, hi]. Clamping happens before converting to int so that huge or
+// infinite coordinates can't overflow. ok is false when the spans don't meet.
+func (s *SpatialIndex) clampSpan(from, to float64, lo, hi int) (int, int, bool) {
+	first, last := math.Floor(from/s.cellSize), math.Floor(to/s.cellSize)
+	if !(first <= float64(hi) && last >= float64(lo) && first <= last) {
+		return 0, 0, false
+	}
+	return int(max(first, float64(lo))), int(min(last, float64(hi))), true
+}
+
+func (s *SpatialIndex) cellOf(x, y float64) gridCell {
+	return gridCell{int(math.Floor(x / s.cellSize)), int(math.Floor(y / s.cellSize))}
+}
+
+func distanceSquared(x1, y1, x2, y2 float64) float64 {
+	return (x1-x2)*(x1-x2) + (y1-y2)*(y1-y2)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,165 @@
+package lib
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func positionOf(p PositionComponent) (float64, float64) {
+	return p.x, p.y
+}
+
+func bruteForce(w *World, match func(p PositionComponent) bool) []EntityID {
+	entities := make([]EntityID, 0)
+	for entity := EntityID(0); entity < EntityID(w.locations.len()); entity++ {
+		if p, ok := Get[PositionComponent](w, entity); ok && match(p) {
+			entities = append(entities, entity)
+		}
+	}
+	return entities
+}
+
+func TestSpatialIndex_MatchesBruteForce(t *testing.T) {
+	w := NewWorld()
+	random := rand.New(rand.NewSource(7))
+	for i := 0; i < 200; i++ {
+		w.AddComponents(w.CreateEntity(), PositionComponent{x: random.Float64()*1000 - 500, y: random.Float64() * 800})
+	}
+	index := NewSpatialIndex(w, 64, positionOf)
+
+	for step := 0; step < 300; step++ {
+		entity := EntityID(random.Intn(w.locations.len()))
+		switch random.Intn(4) {
+		case 0:
+			w.DestroyEntity(entity)
+		case 1:
+			w.AddComponents(w.CreateEntity(), PositionComponent{x: random.Float64() * 1000, y: random.Float64() * 800})
+		default:
+			w.AddComponents(entity, PositionComponent{x: random.Float64()*1000 - 500, y: random.Float64() * 800})
+		}
+	}
+
+	for i := 0; i < 50; i++ {
+		x, y, r := random.Float64()*1000-500, random.Float64()*800, random.Float64()*300
+		expected := bruteForce(w, func(p PositionComponent) bool { return distanceSquared(p.x, p.y, x, y) <= r*r })
+		if got := index.QueryRadius(x, y, r); !reflect.DeepEqual(got, expected) {
+			t.Fatalf("radius query (%v, %v, %v): expected %v, got %v", x, y, r, expected, got)
+		}
+
+		expected = bruteForce(w, func(p PositionComponent) bool { return p.x >= x && p.x <= x+r && p.y >= y && p.y <= y+r })
+		if got := index.QueryRect(x, y, x+r, y+r); !reflect.DeepEqual(got, expected) {
+			t.Fatalf("rect query: expected %v, got %v", expected, got)
+		}
+
+		nearest, found := index.Nearest(x*3, y*3)
+		best := -1.0
+		for _, entity := range bruteForce(w, func(PositionComponent) bool { return true }) {
+			p, _ := Get[PositionComponent](w, entity)
+			if d := distanceSquared(p.x, p.y, x*3, y*3); best < 0 || d < best {
+				best = d
+			}
+		}
+		p, _ := Get[PositionComponent](w, nearest)
+		if !found || distanceSquared(p.x, p.y, x*3, y*3) != best {
+			t.Fatalf("nearest to (%v, %v): got %v at distance² %v, expected %v", x*3, y*3, nearest, distanceSquared(p.x, p.y, x*3, y*3), best)
+		}
+	}
+}
+
+func TestSpatialIndex_Empty(t *testing.T) {
+	w := NewWorld()
+	index := NewSpatialIndex(w, 10, positionOf)
+	entity := w.CreateEntity()
+	w.AddComponents(entity, PositionComponent{x: 1, y: 1})
+	w.RemoveComponent(entity, GetComponentID[PositionComponent]())
+
+	if _, found := index.Nearest(0, 0); found {
+		t.Errorf("expected no nearest entity in an empty index")
+	}
+	if got := index.QueryRadius(0, 0, 100); len(got) != 0 {
+		t.Errorf("expected no entities, got %v", got)
+	}
+}
+
+func TestSpatialIndex_NearestTracksBounds(t *testing.T) {
+	w := NewWorld()
+	index := NewSpatialIndex(w, 10, positionOf)
+	near := w.CreateEntity()
+	w.AddComponents(near, PositionComponent{x: 5, y: 5})
+	far := w.CreateEntity()
+	w.AddComponents(far, PositionComponent{x: 100000, y: -50000})
+
+	if got, _ := index.Nearest(90000, -40000); got != far {
+		t.Errorf("expected %v, got %v", far, got)
+	}
+	w.DestroyEntity(far)
+	if got, found := index.Nearest(-200000, 300000); !found || got != near {
+		t.Errorf("expected %v, got %v", near, got)
+	}
+	if expected := (cellBounds{min: gridCell{0, 0}, max: gridCell{0, 0}}); index.bounds != expected {
+		t.Errorf("expected bounds to shrink to %v, got %v", expected, index.bounds)
+	}
+}
+
+func TestSpatialIndex_NearestFarFromData(t *testing.T) {
+	w := NewWorld()
+	index := NewSpatialIndex(w, 1, positionOf)
+	origin := w.CreateEntity()
+	w.AddComponents(origin, PositionComponent{})
+	corner := w.CreateEntity()
+	w.AddComponents(corner, PositionComponent{x: 3, y: 3})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if got, found := index.Nearest(1e9, 0); !found || got != corner {
+			t.Errorf("expected %v, got %v", corner, got)
+		}
+		if got, found := index.Nearest(-1e9, -1e9); !found || got != origin {
+			t.Errorf("expected %v, got %v", origin, got)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected a far query to skip the empty rings before the data")
+	}
+}
+
+func TestSpatialIndex_QueriesLargerThanData(t *testing.T) {
+	w := NewWorld()
+	index := NewSpatialIndex(w, 10, positionOf)
+	entity := w.CreateEntity()
+	w.AddComponents(entity, PositionComponent{x: 3, y: 4})
+	far := w.CreateEntity()
+	w.AddComponents(far, PositionComponent{x: 1e7, y: -1e7})
+
+	if got := index.QueryRadius(0, 0, 1e6); !reflect.DeepEqual(got, []EntityID{entity}) {
+		t.Errorf("expected [%v], got %v", entity, got)
+	}
+	if got := index.QueryRect(-1e12, -1e12, 1e12, 1e12); !reflect.DeepEqual(got, []EntityID{entity, far}) {
+		t.Errorf("expected [%v %v], got %v", entity, far, got)
+	}
+	if got := index.QueryRect(math.Inf(-1), math.Inf(-1), math.Inf(1), math.Inf(1)); len(got) != 2 {
+		t.Errorf("expected both entities, got %v", got)
+	}
+	if got := index.QueryRadius(5e5, 5e5, 10); len(got) != 0 {
+		t.Errorf("expected no entities outside the data, got %v", got)
+	}
+}
+
+func TestNewSpatialIndex_InvalidCellSizePanics(t *testing.T) {
+	for _, cellSize := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic for cell size %v", cellSize)
+				}
+			}()
+			NewSpatialIndex(NewWorld(), cellSize, positionOf)
+		}()
+	}
+}
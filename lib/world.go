@@ -1,196 +1,512 @@
 package lib
 
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
 type World struct {
-	archetypes       map[Bitset]*Archetype
-	entityArchetypes map[EntityID]Bitset
+	archetypes     map[Bitset]*Archetype
+	archetypeOrder []*Archetype
+
+	// locations is indexed by EntityID. Destroyed entities push their id onto
+	// freeIDs and CreateEntity hands out the most recently freed one first,
+	// so storage indexed by id grows with the peak entity count rather than
+	// with every entity ever created.
+	locations   pages[entityLocation]
+	freeIDs     pages[EntityID]
+	entityCount int
 
-	entities     map[EntityID]bool
-	nextEntityID EntityID
+	systems       []namedSystem
+	systemTimings []SystemStats
 
-	systems []System
+	frame     StructuralChanges
+	lastFrame StructuralChanges
 
 	queryCache *QueryCache
+	logger     *slog.Logger
+	indexes    map[ComponentID][]componentIndex
+	sparseSets map[ComponentID]*sparseSet
+}
+
+// namedSystem keeps the name Stats reports a system under, worked out once
+// when it is added rather than on every Update.
+type namedSystem struct {
+	System
+	name string
+}
+
+// entityLocation is where an entity's table components live; row is -1 until
+// the entity has components.
+type entityLocation struct {
+	archetype Bitset
+	row       int
+	alive     bool
 }
 
 func NewWorld() *World {
 	return &World{
-		archetypes:       make(map[Bitset]*Archetype),
-		entityArchetypes: make(map[EntityID]Bitset),
-		entities:         make(map[EntityID]bool),
-		systems:          make([]System, 0),
-		queryCache:       NewQueryCache(),
+		archetypes: make(map[Bitset]*Archetype),
+		systems:    make([]namedSystem, 0),
+		queryCache: NewQueryCache(),
+		logger:     logger,
+		indexes:    make(map[ComponentID][]componentIndex),
+		sparseSets: make(map[ComponentID]*sparseSet),
 	}
 }
 
 func (w *World) CreateEntity() EntityID {
-	id := w.nextEntityID
-	w.nextEntityID++
-	w.entities[id] = true
-	w.entityArchetypes[id] = 0
+	var id EntityID
+	if free := w.freeIDs.len(); free > 0 {
+		id = w.freeIDs.at(free - 1)
+		w.freeIDs.pop()
+		w.locations.set(int(id), entityLocation{row: -1, alive: true})
+	} else {
+		id = EntityID(w.locations.len())
+		w.locations.append(entityLocation{row: -1, alive: true})
+	}
+	w.entityCount++
+	w.frame.Created++
 	return id
 }
 
+// location reports where an entity lives, if it exists.
+func (w *World) location(entity EntityID) (entityLocation, bool) {
+	if entity >= EntityID(w.locations.len()) {
+		return entityLocation{}, false
+	}
+	location := w.locations.at(int(entity))
+	return location, location.alive
+}
+
 func (w *World) DestroyEntity(entity EntityID) {
-	bitset, exist := w.entityArchetypes[entity]
-	if !exist {
-		return
+	ignoreMissing(w.TryDestroyEntity(entity))
+}
+
+func (w *World) TryDestroyEntity(entity EntityID) error {
+	if err := w.checkEntity(entity); err != nil {
+		return err
 	}
-	archetype, exists := w.archetypes[bitset]
-	if !exists {
-		return
+	w.removeEntity(entity, true)
+	return nil
+}
+
+func (w *World) CloneEntity(entity EntityID) EntityID {
+	clone, err := w.TryCloneEntity(entity)
+	ignoreMissing(err)
+	return clone
+}
+
+func (w *World) TryCloneEntity(entity EntityID) (EntityID, error) {
+	clones, err := w.TryCloneEntities(entity)
+	if err != nil {
+		return 0, err
+	}
+	return clones[0], nil
+}
+
+// CloneEntities copies a group of entities, rewriting references between
+// members of the group so they point at the corresponding clones.
+func (w *World) CloneEntities(entities ...EntityID) []EntityID {
+	clones, err := w.TryCloneEntities(entities...)
+	ignoreMissing(err)
+	return clones
+}
+
+func (w *World) TryCloneEntities(entities ...EntityID) ([]EntityID, error) {
+	if err := w.checkEntities(entities); err != nil {
+		return nil, err
 	}
 
-	entityIdx := -1
-	for idx, id := range archetype.entities {
-		if id == entity {
-			entityIdx = idx
-			break
+	clones := make([]EntityID, len(entities))
+	remap := make(map[EntityID]EntityID, len(entities))
+	for i, entity := range entities {
+		clones[i] = w.CreateEntity()
+		remap[entity] = clones[i]
+	}
+
+	for i, entity := range entities {
+		components, disabledMask := w.entityComponents(entity)
+		for componentID, component := range components {
+			component = Registry.cloneComponent(componentID, component)
+			components[componentID] = Registry.remapComponent(componentID, component, remap)
 		}
+		w.setEntityComponents(clones[i], components, disabledMask)
 	}
+	return clones, nil
+}
 
-	if entityIdx == -1 {
-		return
+func (w *World) MoveEntityTo(other *World, entity EntityID) EntityID {
+	moved, err := w.TryMoveEntityTo(other, entity)
+	ignoreMissing(err)
+	return moved
+}
+
+func (w *World) TryMoveEntityTo(other *World, entity EntityID) (EntityID, error) {
+	moved, err := w.TryMoveEntitiesTo(other, entity)
+	if err != nil {
+		return 0, err
 	}
+	return moved[0], nil
+}
 
-	lastIdx := len(archetype.entities) - 1
-	archetype.entities[entityIdx] = archetype.entities[lastIdx]
-	archetype.entities = archetype.entities[:lastIdx]
-	delete(archetype.disabledMaskPerEntity, entity)
+// MoveEntitiesTo transfers a group of entities into another world. Components
+// keep their values, so neither clone nor remove hooks run; references within
+// the group are rewritten to the new ids.
+func (w *World) MoveEntitiesTo(other *World, entities ...EntityID) []EntityID {
+	moved, err := w.TryMoveEntitiesTo(other, entities...)
+	ignoreMissing(err)
+	return moved
+}
 
-	if len(archetype.entities) == 0 {
-		delete(w.archetypes, bitset)
-	} else {
-		for componentId, components := range archetype.components {
-			components[entityIdx] = components[lastIdx]
-			archetype.components[componentId] = components[:lastIdx]
-		}
+func (w *World) TryMoveEntitiesTo(other *World, entities ...EntityID) ([]EntityID, error) {
+	if err := w.checkEntities(entities); err != nil {
+		return nil, err
 	}
-	w.archetypes[bitset] = archetype
 
-	delete(w.entityArchetypes, entity)
-	delete(w.entities, entity)
+	moved := make([]EntityID, len(entities))
+	remap := make(map[EntityID]EntityID, len(entities))
+	for i, entity := range entities {
+		moved[i] = other.CreateEntity()
+		remap[entity] = moved[i]
+	}
+
+	for i, entity := range entities {
+		components, disabledMask := w.entityComponents(entity)
+		for componentID, component := range components {
+			components[componentID] = Registry.remapComponent(componentID, component, remap)
+		}
+		other.setEntityComponents(moved[i], components, disabledMask)
+		w.removeEntity(entity, false)
+	}
+	return moved, nil
 }
 
 func (w *World) AddComponents(entity EntityID, components ...interface{}) {
-	oldBitset := w.entityArchetypes[entity]
+	ignoreMissing(w.TryAddComponents(entity, components...))
+}
+
+func (w *World) TryAddComponents(entity EntityID, components ...interface{}) error {
+	if err := w.checkEntity(entity); err != nil {
+		return err
+	}
+	location, _ := w.location(entity)
+	oldBitset := location.archetype
 	newBitset := oldBitset
+	componentIDs := make([]ComponentID, 0, len(components))
 	for _, component := range components {
-		componentID := GetComponentIDOf(component)
-		newBitset = newBitset.AddID(componentID)
+		componentID, err := TryGetComponentIDOf(component)
+		if err != nil {
+			return err
+		}
+		componentIDs = append(componentIDs, componentID)
+	}
+
+	tableComponents := make([]interface{}, 0, len(components))
+	for i, componentID := range componentIDs {
+		if replaced, exists := w.componentValue(entity, componentID); exists {
+			Registry.removeComponent(componentID, entity, replaced)
+		}
 		w.queryCache.Invalidate(componentID)
+		w.setIndexed(entity, componentID, components[i])
+		if Registry.isSparse(componentID) {
+			w.sparseSet(componentID).set(entity, components[i])
+		} else {
+			tableComponents = append(tableComponents, components[i])
+			newBitset = newBitset.AddID(componentID)
+		}
 	}
-	if oldBitset == newBitset {
-		w.updateEntityComponent(entity, newBitset, components...)
+
+	// Every entity with components has a row, even if only sparse ones, so
+	// queries can always start from archetypes.
+	if _, row := w.entityRow(entity); row != -1 && oldBitset == newBitset {
+		w.updateEntityComponent(entity, tableComponents...)
 	} else {
-		w.moveEntityToArchetype(entity, oldBitset, newBitset, components)
+		w.moveEntityToArchetype(entity, oldBitset, newBitset, tableComponents)
 	}
+	return nil
 }
 
 func (w *World) DisableComponent(entity EntityID, componentID ComponentID) {
-	bitset := w.entityArchetypes[entity]
+	ignoreMissing(w.TryDisableComponent(entity, componentID))
+}
 
-	disabledMask, exists := w.archetypes[bitset].disabledMaskPerEntity[entity]
-	if !exists {
-		disabledMask = 0
+func (w *World) TryDisableComponent(entity EntityID, componentID ComponentID) error {
+	if err := w.checkComponent(entity, componentID); err != nil {
+		return err
 	}
-	w.archetypes[bitset].disabledMaskPerEntity[entity] = disabledMask.AddID(componentID)
+	w.setDisabled(entity, componentID, true)
 	w.queryCache.Invalidate(componentID)
+	return nil
 }
 
 func (w *World) EnableComponent(entity EntityID, componentID ComponentID) {
-	bitset := w.entityArchetypes[entity]
-	disabledMask, exists := w.archetypes[bitset].disabledMaskPerEntity[entity]
-	if !exists {
-		disabledMask = 0
+	ignoreMissing(w.TryEnableComponent(entity, componentID))
+}
+
+func (w *World) TryEnableComponent(entity EntityID, componentID ComponentID) error {
+	if err := w.checkComponent(entity, componentID); err != nil {
+		return err
 	}
-	w.archetypes[bitset].disabledMaskPerEntity[entity] = disabledMask.RemoveID(componentID)
+	w.setDisabled(entity, componentID, false)
 	w.queryCache.Invalidate(componentID)
+	return nil
+}
+
+func (w *World) AddDefaultComponents(entity EntityID, componentIDs ...ComponentID) {
+	ignoreMissing(w.TryAddDefaultComponents(entity, componentIDs...))
+}
+
+func (w *World) TryAddDefaultComponents(entity EntityID, componentIDs ...ComponentID) error {
+	components := make([]interface{}, 0, len(componentIDs))
+	for _, componentID := range componentIDs {
+		if err := checkRegistered(componentID); err != nil {
+			return err
+		}
+		components = append(components, Registry.newComponent(componentID))
+	}
+	return w.TryAddComponents(entity, components...)
 }
 
 func (w *World) RemoveComponent(entity EntityID, componentID ComponentID) {
-	oldBitset := w.entityArchetypes[entity]
+	ignoreMissing(w.TryRemoveComponent(entity, componentID))
+}
+
+func (w *World) TryRemoveComponent(entity EntityID, componentID ComponentID) error {
+	if err := w.checkComponent(entity, componentID); err != nil {
+		return err
+	}
+	component, _ := w.componentValue(entity, componentID)
+	Registry.removeComponent(componentID, entity, component)
+	w.unsetIndexed(entity, componentID)
+	w.queryCache.Invalidate(componentID)
+
+	if Registry.isSparse(componentID) {
+		w.sparseSets[componentID].remove(entity)
+		return nil
+	}
+	w.setDisabled(entity, componentID, false)
+	location, _ := w.location(entity)
+	oldBitset := location.archetype
 	newBitset := oldBitset.RemoveID(componentID)
 	w.moveEntityToArchetype(entity, oldBitset, newBitset, nil)
-	w.queryCache.Invalidate(componentID)
+	return nil
 }
 
 func (w *World) AddSystem(system System) {
-	w.systems = append(w.systems, system)
+	w.systems = append(w.systems, namedSystem{System: system, name: fmt.Sprintf("%T", system)})
 }
 
+// Update runs every system once and marks a frame boundary for Stats.
 func (w *World) Update(deltaTime float64) {
+	w.systemTimings = w.systemTimings[:0]
 	for _, system := range w.systems {
+		start := time.Now()
 		system.Update(w, deltaTime)
+		w.systemTimings = append(w.systemTimings, SystemStats{
+			Name:     system.name,
+			Duration: time.Since(start),
+		})
 	}
+	w.lastFrame, w.frame = w.frame, StructuralChanges{}
 }
 
 func (w *World) moveEntityToArchetype(entity EntityID, oldBitset, newBitset Bitset, components []interface{}) {
-	oldArchetype, oldExists := w.archetypes[oldBitset]
-	newArchetype, exists := w.archetypes[newBitset]
+	oldArchetype, oldRow := w.entityRow(entity)
+	newArchetype := w.archetypeFor(newBitset, len(components))
+
+	// If entity was in an old archetype, copy the Components it keeps
+	var disabled Bitset
+	if oldRow != -1 {
+		disabled = oldArchetype.disabled.at(oldRow) & newBitset
+	}
+	row := newArchetype.appendRow(entity, disabled)
+	if oldRow != -1 {
+		for id, column := range oldArchetype.components {
+			if newBitset.HasID(id) { // If component should exist in new archetype
+				newArchetype.setValue(id, row, column.at(oldRow))
+			}
+		}
+		w.removeRow(oldArchetype, oldRow)
+	}
+
+	// Add new component if provided
+	for _, component := range components {
+		newArchetype.setValue(GetComponentIDOf(component), row, component)
+	}
+
+	w.locations.set(int(entity), entityLocation{archetype: newBitset, row: row, alive: true})
+	w.frame.Moved++
+	if w.debugEnabled() {
+		w.logger.Debug("entity moved", "entity", entity, "from", oldBitset, "to", newBitset, "components", loggedComponents(newBitset))
+	}
+}
+
+// archetypeFor returns the archetype for a bitset, creating it if needed.
+// Archetypes are never dropped, so archetypeOrder gives queries a stable
+// iteration order.
+func (w *World) archetypeFor(bitset Bitset, componentsCapacity int) *Archetype {
+	archetype, exists := w.archetypes[bitset]
 	if !exists {
-		newArchetype = NewArchetype(newBitset, 0, len(components))
-		w.archetypes[newBitset] = newArchetype
+		archetype = NewArchetype(bitset, 0, componentsCapacity)
+		w.archetypes[bitset] = archetype
+		w.archetypeOrder = append(w.archetypeOrder, archetype)
+		w.logger.Debug("archetype created", "archetype", bitset, "components", loggedComponents(bitset))
 	}
+	return archetype
+}
 
-	// If entity was in an old archetype, move its Components
-	if oldExists {
-		entityIndex := -1
-		for i, e := range oldArchetype.entities {
-			if e == entity {
-				entityIndex = i
-				break
-			}
+func (w *World) updateEntityComponent(entity EntityID, components ...interface{}) {
+	archetype, entityIdx := w.entityRow(entity)
+	if entityIdx == -1 {
+		return
+	}
+	for _, component := range components {
+		if componentID := GetComponentIDOf(component); !Registry.isTag(componentID) {
+			archetype.setValue(componentID, entityIdx, component)
 		}
+	}
+}
 
-		if entityIndex != -1 {
-			newArchetype.disabledMaskPerEntity[entity] = oldArchetype.disabledMaskPerEntity[entity]
-
-			// Copy Components to new archetype
-			for id, components := range oldArchetype.components {
-				if newBitset.HasID(id) { // If component should exist in new archetype
-					newArchetype.components[id] = append(
-						newArchetype.components[id],
-						components[entityIndex],
-					)
-				}
-			}
+func (w *World) entityComponents(entity EntityID) (map[ComponentID]interface{}, Bitset) {
+	components := make(map[ComponentID]interface{})
+	archetype, entityIdx := w.entityRow(entity)
+	if entityIdx == -1 {
+		return components, 0
+	}
+	for _, componentID := range archetype.bitset.IDs() {
+		components[componentID] = archetype.value(componentID, entityIdx)
+	}
+	present, disabled := w.sparseMask(entity)
+	for _, componentID := range present.IDs() {
+		components[componentID], _ = w.sparseValue(entity, componentID)
+	}
+	return components, archetype.disabled.at(entityIdx) | disabled
+}
+
+func (w *World) setEntityComponents(entity EntityID, components map[ComponentID]interface{}, disabledMask Bitset) {
+	if len(components) == 0 {
+		return
+	}
+	values := make([]interface{}, 0, len(components))
+	for _, component := range components {
+		values = append(values, component)
+	}
+	w.AddComponents(entity, values...)
+	for _, componentID := range disabledMask.IDs() {
+		w.setDisabled(entity, componentID, true)
+	}
+}
+
+// componentValue reads a component whether or not it is disabled.
+func (w *World) componentValue(entity EntityID, componentID ComponentID) (interface{}, bool) {
+	if Registry.isSparse(componentID) {
+		return w.sparseValue(entity, componentID)
+	}
+	archetype, row := w.entityRow(entity)
+	if row == -1 || !archetype.bitset.HasID(componentID) {
+		return nil, false
+	}
+	return archetype.value(componentID, row), true
+}
 
-			// Remove from old archetype
-			for id, components := range oldArchetype.components {
-				oldArchetype.components[id] = append(
-					components[:entityIndex],
-					components[entityIndex+1:]...,
-				)
+// eachComponent visits every stored value of a component, disabled or not.
+func (w *World) eachComponent(componentID ComponentID, fn func(EntityID, interface{})) {
+	if Registry.isSparse(componentID) {
+		if set, exists := w.sparseSets[componentID]; exists {
+			for row, entity := range set.entities.all() {
+				fn(entity, set.values.at(row))
 			}
-			oldArchetype.entities = append(
-				oldArchetype.entities[:entityIndex],
-				oldArchetype.entities[entityIndex+1:]...,
-			)
 		}
+		return
 	}
-
-	// Add new component if provided
-	if components != nil {
-		for _, component := range components {
-			componentID := GetComponentIDOf(component)
-			components := newArchetype.components[componentID]
-			newArchetype.components[componentID] = append(components, component)
+	for _, archetype := range w.archetypeOrder {
+		if !archetype.bitset.HasID(componentID) {
+			continue
 		}
+		for row, entity := range archetype.entities.all() {
+			fn(entity, archetype.value(componentID, row))
+		}
+	}
+}
+
+func (w *World) isDisabled(entity EntityID, componentID ComponentID) bool {
+	if Registry.isSparse(componentID) {
+		set, exists := w.sparseSets[componentID]
+		return exists && set.isDisabled(entity)
 	}
+	archetype, row := w.entityRow(entity)
+	return row != -1 && archetype.disabled.at(row).HasID(componentID)
+}
 
-	// Add entity to new archetype
-	newArchetype.entities = append(newArchetype.entities, entity)
-	w.entityArchetypes[entity] = newBitset
+func (w *World) setDisabled(entity EntityID, componentID ComponentID, disabled bool) {
+	if Registry.isSparse(componentID) {
+		w.sparseSet(componentID).setDisabled(entity, disabled)
+		return
+	}
+	archetype, row := w.entityRow(entity)
+	if row == -1 {
+		return
+	}
+	mask := archetype.disabled.at(row)
+	if disabled {
+		mask = mask.AddID(componentID)
+	} else {
+		mask = mask.RemoveID(componentID)
+	}
+	if mask != archetype.disabled.at(row) {
+		archetype.disabled.set(row, mask)
+	}
 }
 
-func (w *World) updateEntityComponent(entity EntityID, bitset Bitset, components ...interface{}) {
+func (w *World) removeEntity(entity EntityID, runHooks bool) {
+	location, exist := w.location(entity)
+	if !exist {
+		return
+	}
+	bitset := location.archetype
+	present, _ := w.sparseMask(entity)
+	for _, componentID := range present.IDs() {
+		if runHooks {
+			component, _ := w.sparseValue(entity, componentID)
+			Registry.removeComponent(componentID, entity, component)
+		}
+		w.unsetIndexed(entity, componentID)
+		w.queryCache.Invalidate(componentID)
+		w.sparseSets[componentID].remove(entity)
+	}
+	w.locations.set(int(entity), entityLocation{})
+	w.freeIDs.append(entity)
+	w.entityCount--
+	w.frame.Destroyed++
+	if w.debugEnabled() {
+		w.logger.Debug("entity destroyed", "entity", entity, "archetype", bitset)
+	}
+
+	entityIdx := location.row
+	if entityIdx == -1 {
+		return
+	}
 	archetype := w.archetypes[bitset]
-	for _, component := range components {
-		id := GetComponentIDOf(component)
-		for i, e := range archetype.entities {
-			if e == entity {
-				archetype.components[id][i] = component
-			}
+
+	for _, componentId := range bitset.IDs() {
+		if runHooks {
+			Registry.removeComponent(componentId, entity, archetype.value(componentId, entityIdx))
 		}
+		w.unsetIndexed(entity, componentId)
+		w.queryCache.Invalidate(componentId)
+	}
+
+	w.removeRow(archetype, entityIdx)
+}
+
+// removeRow drops a row from an archetype and points the entity swapped into
+// its place at the new row.
+func (w *World) removeRow(archetype *Archetype, row int) {
+	if moved, ok := archetype.removeRow(row); ok {
+		location := w.locations.at(int(moved))
+		location.row = row
+		w.locations.set(int(moved), location)
 	}
 }
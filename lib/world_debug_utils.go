@@ -1,20 +1,16 @@
 package lib
 
-import "fmt"
+import "os"
 
 func (w *World) Log() {
-	fmt.Printf("----------------------------------------------------------------")
-	for bitset, archetype := range w.archetypes {
-		fmt.Printf("Archetype (bitset: %b):\n", bitset)
-		for componentID, components := range archetype.components {
-			fmt.Printf("  Component ComponentID %d:\n", componentID)
-			for _, component := range components {
-				fmt.Printf("	%v\n", component)
-			}
-		}
-	}
+	_ = w.Inspect(os.Stdout, InspectText)
+}
+
+func (w *World) HasEntity(entity EntityID) bool {
+	_, exists := w.location(entity)
+	return exists
 }
 
 func (w *World) GetEntityCount() int {
-	return len(w.entities)
+	return w.entityCount
 }
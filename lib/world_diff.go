@@ -0,0 +1,103 @@
+package lib
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DisabledField is the Field of a FieldChange reporting that a component was
+// enabled or disabled.
+const DisabledField = "(disabled)"
+
+// WorldDiff lists what changed going from one world to another. Entities are
+// matched by ID.
+type WorldDiff struct {
+	Added   []EntityID    `json:"added,omitempty"`
+	Removed []EntityID    `json:"removed,omitempty"`
+	Changed []FieldChange `json:"changed,omitempty"`
+}
+
+// FieldChange is one differing field of an entity present in both worlds. An
+// empty Field means the whole component was added (Old is nil) or removed
+// (New is nil).
+type FieldChange struct {
+	Entity    EntityID    `json:"entity"`
+	Component string      `json:"component"`
+	Field     string      `json:"field,omitempty"`
+	Old       interface{} `json:"old"`
+	New       interface{} `json:"new"`
+}
+
+func Diff(a, b *World) WorldDiff {
+	var diff WorldDiff
+	for _, entity := range a.sortedEntities() {
+		if !b.HasEntity(entity) {
+			diff.Removed = append(diff.Removed, entity)
+		}
+	}
+	for _, entity := range b.sortedEntities() {
+		if !a.HasEntity(entity) {
+			diff.Added = append(diff.Added, entity)
+			continue
+		}
+		diff.Changed = append(diff.Changed, diffEntity(a, b, entity)...)
+	}
+	return diff
+}
+
+func (d WorldDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func (d WorldDiff) String() string {
+	var b strings.Builder
+	for _, entity := range d.Added {
+		fmt.Fprintf(&b, "+ entity %d\n", entity)
+	}
+	for _, entity := range d.Removed {
+		fmt.Fprintf(&b, "- entity %d\n", entity)
+	}
+	for _, change := range d.Changed {
+		name := change.Component
+		if change.Field != "" {
+			name += "." + change.Field
+		}
+		fmt.Fprintf(&b, "~ entity %d %s: %v -> %v\n", change.Entity, name, change.Old, change.New)
+	}
+	return b.String()
+}
+
+func diffEntity(a, b *World, entity EntityID) []FieldChange {
+	var changes []FieldChange
+	for _, componentID := range (a.componentMask(entity) | b.componentMask(entity)).IDs() {
+		if Registry.isUnhashed(componentID) {
+			continue
+		}
+		name := componentName(componentID)
+		old, inA := a.componentValue(entity, componentID)
+		current, inB := b.componentValue(entity, componentID)
+		if !inA || !inB {
+			changes = append(changes, FieldChange{Entity: entity, Component: name, Old: old, New: current})
+			continue
+		}
+
+		oldFields, _ := inspectFields(name, old)
+		newFields, _ := inspectFields(name, current)
+		for i := range oldFields {
+			if !reflect.DeepEqual(oldFields[i].Value, newFields[i].Value) {
+				changes = append(changes, FieldChange{
+					Entity:    entity,
+					Component: name,
+					Field:     oldFields[i].Name,
+					Old:       oldFields[i].Value,
+					New:       newFields[i].Value,
+				})
+			}
+		}
+		if oldDisabled, newDisabled := a.isDisabled(entity, componentID), b.isDisabled(entity, componentID); oldDisabled != newDisabled {
+			changes = append(changes, FieldChange{Entity: entity, Component: name, Field: DisabledField, Old: oldDisabled, New: newDisabled})
+		}
+	}
+	return changes
+}
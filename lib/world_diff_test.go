@@ -0,0 +1,67 @@
+package lib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := NewWorld()
+	kept, removed := a.CreateEntity(), a.CreateEntity()
+	a.AddComponents(kept, layerComponent{Layer: 1, Name: "ground"}, CharacterComponent{name: "hero"})
+	a.AddComponents(removed, PositionComponent{})
+
+	b := NewWorld()
+	b.CreateEntity()
+	b.CreateEntity()
+	added := b.CreateEntity()
+	b.AddComponents(kept, layerComponent{Layer: 2, Name: "ground"}, PositionComponent{x: 1})
+	b.AddComponents(added, PositionComponent{})
+	b.DestroyEntity(removed)
+	b.AddComponents(kept, selectedComponent{})
+	b.DisableComponent(kept, selectedComponentID)
+
+	diff := Diff(a, b)
+	if !reflect.DeepEqual(diff.Added, []EntityID{added}) || !reflect.DeepEqual(diff.Removed, []EntityID{removed}) {
+		t.Errorf("expected %d added and %d removed, got %+v", added, removed, diff)
+	}
+
+	expected := map[string]FieldChange{
+		"layerComponent.Layer": {Entity: kept, Component: "layerComponent", Field: "Layer", Old: int32(1), New: int32(2)},
+		"CharacterComponent":   {Entity: kept, Component: "CharacterComponent", Old: CharacterComponent{name: "hero"}},
+		"PositionComponent":    {Entity: kept, Component: "PositionComponent", New: PositionComponent{x: 1}},
+		"selectedComponent":    {Entity: kept, Component: "selectedComponent", New: selectedComponent{}},
+	}
+	if len(diff.Changed) != len(expected) {
+		t.Fatalf("expected %d changes, got:\n%s", len(expected), diff)
+	}
+	for _, change := range diff.Changed {
+		key := change.Component
+		if change.Field != "" {
+			key += "." + change.Field
+		}
+		if !reflect.DeepEqual(change, expected[key]) {
+			t.Errorf("unexpected change %+v", change)
+		}
+	}
+
+	if !Diff(b, b).Empty() {
+		t.Error("expected no difference between a world and itself")
+	}
+}
+
+func TestDiff_Disabled(t *testing.T) {
+	a, b := NewWorld(), NewWorld()
+	var entity EntityID
+	for _, w := range []*World{a, b} {
+		entity = w.CreateEntity()
+		w.AddComponents(entity, PositionComponent{})
+	}
+	b.DisableComponent(entity, GetComponentID[PositionComponent]())
+
+	diff := Diff(a, b)
+	expected := []FieldChange{{Entity: entity, Component: "PositionComponent", Field: DisabledField, Old: false, New: true}}
+	if !reflect.DeepEqual(diff.Changed, expected) {
+		t.Errorf("expected %+v, got %+v", expected, diff.Changed)
+	}
+}
@@ -0,0 +1,127 @@
+package lib
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+)
+
+// Hash digests every entity with its component values and disabled flags.
+// Entities are visited in ID order, components by ID and fields in declaration
+// order, so two worlds in the same state hash equally however they got there.
+// Components registered as Unhashed are skipped.
+func (w *World) Hash() uint64 {
+	h := fnv.New64a()
+	for _, entity := range w.sortedEntities() {
+		hashUint(h, uint64(entity))
+		for _, componentID := range w.ComponentsOf(entity) {
+			if Registry.isUnhashed(componentID) {
+				continue
+			}
+			component, _ := w.componentValue(entity, componentID)
+			hashString(h, Registry.idToType[componentID].String())
+			hashBool(h, w.isDisabled(entity, componentID))
+			hashValue(h, reflect.ValueOf(component), nil)
+		}
+	}
+	return h.Sum64()
+}
+
+func (w *World) sortedEntities() []EntityID {
+	entities := make([]EntityID, 0, w.entityCount)
+	for id, location := range w.locations.all() {
+		if location.alive {
+			entities = append(entities, EntityID(id))
+		}
+	}
+	return entities
+}
+
+func hashUint(h hash.Hash64, value uint64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], value)
+	h.Write(buf[:])
+}
+
+func hashBool(h hash.Hash64, value bool) {
+	if value {
+		h.Write([]byte{1})
+	} else {
+		h.Write([]byte{0})
+	}
+}
+
+func hashString(h hash.Hash64, value string) {
+	hashUint(h, uint64(len(value)))
+	h.Write([]byte(value))
+}
+
+// hashValue walks a value with reflection, so unexported fields count too.
+// Map entries are hashed separately and summed to stay independent of
+// iteration order. path holds the pointers being followed; one that points
+// back into it is hashed as the distance to that ancestor, so cycles like
+// parent pointers terminate and still hash the same in every run.
+func hashValue(h hash.Hash64, value reflect.Value, path []reflect.Value) {
+	if !value.IsValid() {
+		h.Write([]byte{0})
+		return
+	}
+	h.Write([]byte{byte(value.Kind())})
+	switch value.Kind() {
+	case reflect.Bool:
+		hashBool(h, value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		hashUint(h, uint64(value.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		hashUint(h, value.Uint())
+	case reflect.Float32, reflect.Float64:
+		hashUint(h, math.Float64bits(value.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		hashUint(h, math.Float64bits(real(value.Complex())))
+		hashUint(h, math.Float64bits(imag(value.Complex())))
+	case reflect.String:
+		hashString(h, value.String())
+	case reflect.Array, reflect.Slice:
+		hashUint(h, uint64(value.Len()))
+		for i := 0; i < value.Len(); i++ {
+			hashValue(h, value.Index(i), path)
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			hashValue(h, value.Field(i), path)
+		}
+	case reflect.Map:
+		var sum uint64
+		iter := value.MapRange()
+		for iter.Next() {
+			entry := fnv.New64a()
+			hashValue(entry, iter.Key(), path)
+			hashValue(entry, iter.Value(), path)
+			sum += entry.Sum64()
+		}
+		hashUint(h, uint64(value.Len()))
+		hashUint(h, sum)
+	case reflect.Pointer:
+		hashBool(h, value.IsNil())
+		if value.IsNil() {
+			return
+		}
+		for depth := len(path) - 1; depth >= 0; depth-- {
+			if path[depth].Pointer() == value.Pointer() && path[depth].Type() == value.Type() {
+				hashBool(h, true)
+				hashUint(h, uint64(len(path)-depth))
+				return
+			}
+		}
+		hashBool(h, false)
+		hashValue(h, value.Elem(), append(path, value))
+	case reflect.Interface:
+		hashBool(h, value.IsNil())
+		if !value.IsNil() {
+			hashString(h, value.Elem().Type().String())
+			hashValue(h, value.Elem(), path)
+		}
+	}
+}
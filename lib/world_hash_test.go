@@ -0,0 +1,113 @@
+package lib
+
+import "testing"
+
+type inventoryComponent struct {
+	items map[string]int
+}
+
+type labelComponent struct {
+	text string
+}
+
+var _ = RegisterComponent[inventoryComponent]()
+var _ = RegisterComponent[labelComponent](Unhashed())
+
+func TestWorld_Hash(t *testing.T) {
+	build := func(reverse bool) *World {
+		w := NewWorld()
+		a, b := w.CreateEntity(), w.CreateEntity()
+		items := map[string]int{}
+		keys := []string{"sword", "shield", "potion", "arrow"}
+		if reverse {
+			w.AddComponents(b, selectedComponent{Order: 2})
+			w.AddComponents(a, PositionComponent{x: 1, y: 2})
+			w.AddComponents(a, CharacterComponent{name: "hero"})
+			for i := len(keys) - 1; i >= 0; i-- {
+				items[keys[i]] = i
+			}
+		} else {
+			w.AddComponents(a, CharacterComponent{name: "hero"}, PositionComponent{x: 1, y: 2})
+			w.AddComponents(b, selectedComponent{Order: 2})
+			for i, key := range keys {
+				items[key] = i
+			}
+		}
+		w.AddComponents(b, inventoryComponent{items: items})
+		return w
+	}
+
+	if build(false).Hash() != build(true).Hash() {
+		t.Fatal("expected equal worlds to hash equally regardless of build order")
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(w *World)
+	}{
+		{"field", func(w *World) { w.AddComponents(0, PositionComponent{x: 1, y: 3}) }},
+		{"sparse field", func(w *World) { w.AddComponents(1, selectedComponent{Order: 3}) }},
+		{"map entry", func(w *World) {
+			w.AddComponents(1, inventoryComponent{items: map[string]int{"sword": 0}})
+		}},
+		{"disabled", func(w *World) { w.DisableComponent(0, GetComponentID[PositionComponent]()) }},
+		{"removed component", func(w *World) { w.RemoveComponent(0, GetComponentID[CharacterComponent]()) }},
+		{"new entity", func(w *World) { w.CreateEntity() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := build(false)
+			before := w.Hash()
+			tt.mutate(w)
+			if w.Hash() == before {
+				t.Error("expected hash to change")
+			}
+		})
+	}
+}
+
+type treeNode struct {
+	name     string
+	parent   *treeNode
+	children []*treeNode
+}
+
+type treeComponent struct {
+	root *treeNode
+}
+
+var _ = RegisterComponent[treeComponent]()
+
+func TestWorld_HashCyclicComponent(t *testing.T) {
+	build := func(childName string) *World {
+		root := &treeNode{name: "root"}
+		child := &treeNode{name: childName, parent: root}
+		root.children = append(root.children, child)
+		w := NewWorld()
+		w.AddComponents(w.CreateEntity(), treeComponent{root: root})
+		return w
+	}
+
+	if build("leaf").Hash() != build("leaf").Hash() {
+		t.Error("expected equal cyclic components to hash equally")
+	}
+	if build("leaf").Hash() == build("branch").Hash() {
+		t.Error("expected different cyclic components to hash differently")
+	}
+}
+
+func TestWorld_HashSkipsUnhashed(t *testing.T) {
+	build := func(text string) *World {
+		w := NewWorld()
+		w.AddComponents(w.CreateEntity(), PositionComponent{x: 1}, labelComponent{text: text})
+		return w
+	}
+
+	a, b := build("FPS: 60"), build("FPS: 30")
+	if a.Hash() != b.Hash() {
+		t.Error("expected unhashed components to leave the hash alone")
+	}
+	if diff := Diff(a, b); !diff.Empty() {
+		t.Errorf("expected unhashed components to leave the diff empty, got\n%s", diff)
+	}
+}
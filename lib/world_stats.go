@@ -0,0 +1,93 @@
+package lib
+
+import (
+	"reflect"
+	"time"
+)
+
+type WorldStats struct {
+	Entities          int              `json:"entities"`
+	ArchetypeCount    int              `json:"archetypeCount"`
+	Archetypes        []ArchetypeStats `json:"archetypes"`
+	SparseSets        []SparseSetStats `json:"sparseSets"`
+	ColumnBytes       int              `json:"columnBytes"`
+	QueryCacheHits    int              `json:"queryCacheHits"`
+	QueryCacheMisses  int              `json:"queryCacheMisses"`
+	QueryCacheHitRate float64          `json:"queryCacheHitRate"`
+
+	// Structural changes made during the last call to Update, and outside
+	// Update since the call before it.
+	StructuralChanges StructuralChanges `json:"structuralChanges"`
+	// Timings of the systems run by the last call to Update.
+	Systems []SystemStats `json:"systems"`
+}
+
+type ArchetypeStats struct {
+	Bitset      Bitset        `json:"bitset"`
+	Components  []ComponentID `json:"components"`
+	Entities    int           `json:"entities"`
+	ColumnBytes int           `json:"columnBytes"`
+}
+
+type SparseSetStats struct {
+	Component ComponentID `json:"component"`
+	Entities  int         `json:"entities"`
+	Bytes     int         `json:"bytes"`
+}
+
+type StructuralChanges struct {
+	Created   int `json:"created"`
+	Destroyed int `json:"destroyed"`
+	Moved     int `json:"moved"`
+}
+
+type SystemStats struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+}
+
+func (w *World) Stats() WorldStats {
+	stats := WorldStats{
+		Entities:          w.entityCount,
+		ArchetypeCount:    len(w.archetypeOrder),
+		Archetypes:        make([]ArchetypeStats, 0, len(w.archetypeOrder)),
+		SparseSets:        make([]SparseSetStats, 0, len(w.sparseSets)),
+		QueryCacheHits:    w.queryCache.hits,
+		QueryCacheMisses:  w.queryCache.misses,
+		StructuralChanges: w.lastFrame,
+		Systems:           append([]SystemStats(nil), w.systemTimings...),
+	}
+	if lookups := w.queryCache.hits + w.queryCache.misses; lookups > 0 {
+		stats.QueryCacheHitRate = float64(w.queryCache.hits) / float64(lookups)
+	}
+
+	for _, archetype := range w.archetypeOrder {
+		archetypeStats := ArchetypeStats{
+			Bitset:     archetype.bitset,
+			Components: archetype.bitset.IDs(),
+			Entities:   archetype.len(),
+		}
+		for componentID, column := range archetype.components {
+			archetypeStats.ColumnBytes += columnBytes(componentID, column)
+		}
+		stats.ColumnBytes += archetypeStats.ColumnBytes
+		stats.Archetypes = append(stats.Archetypes, archetypeStats)
+	}
+	for _, componentID := range Registry.sparse.IDs() {
+		if set, exists := w.sparseSets[componentID]; exists {
+			stats.SparseSets = append(stats.SparseSets, SparseSetStats{
+				Component: componentID,
+				Entities:  set.len(),
+				Bytes:     columnBytes(componentID, &set.values),
+			})
+		}
+	}
+	return stats
+}
+
+// columnBytes estimates a column's footprint: the interface slots of its
+// pages plus one boxed value per stored component.
+func columnBytes(componentID ComponentID, column *pages[interface{}]) int {
+	slot := int(reflect.TypeFor[interface{}]().Size())
+	return len(column.table)*pageSize*slot + column.len()*int(Registry.idToType[componentID].Size())
+}
@@ -0,0 +1,78 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+)
+
+type spawnSystem struct {
+	count int
+}
+
+func (s *spawnSystem) Update(w *World, deltaTime float64) {
+	for i := 0; i < s.count; i++ {
+		w.AddComponents(w.CreateEntity(), PositionComponent{})
+	}
+}
+
+func TestWorld_Stats(t *testing.T) {
+	w := NewWorld()
+	spawner := &spawnSystem{count: 4}
+	w.AddSystem(spawner)
+	entity := w.CreateEntity()
+	w.AddComponents(entity, CharacterComponent{}, IsEnabledComponent{})
+
+	w.Update(0.016)
+	w.AddComponents(entity, selectedComponent{})
+	spawner.count = 2
+	w.Update(0.016)
+	w.Query().With(GetComponentID[PositionComponent]()).Get()
+	w.Query().With(GetComponentID[PositionComponent]()).Get()
+
+	stats := w.Stats()
+	if stats.Entities != 7 || stats.ArchetypeCount != 2 {
+		t.Errorf("expected 7 entities in 2 archetypes, got %d in %d", stats.Entities, stats.ArchetypeCount)
+	}
+	if stats.Archetypes[1].Entities != 6 || len(stats.Archetypes[0].Components) != 2 {
+		t.Errorf("unexpected archetype stats %+v", stats.Archetypes)
+	}
+	if stats.Archetypes[0].ColumnBytes == 0 || stats.ColumnBytes < stats.Archetypes[0].ColumnBytes {
+		t.Errorf("expected column memory to be counted, got %+v", stats)
+	}
+	if len(stats.SparseSets) != 1 || stats.SparseSets[0].Entities != 1 {
+		t.Errorf("expected one sparse set with one entity, got %+v", stats.SparseSets)
+	}
+	if stats.QueryCacheHits != 1 || stats.QueryCacheMisses != 1 || stats.QueryCacheHitRate != 0.5 {
+		t.Errorf("expected one hit and one miss, got %+v", stats)
+	}
+	expected := StructuralChanges{Created: 2, Moved: 2}
+	if stats.StructuralChanges != expected {
+		t.Errorf("expected only the second frame's changes %+v, got %+v", expected, stats.StructuralChanges)
+	}
+	if len(stats.Systems) != 1 || !strings.Contains(stats.Systems[0].Name, "spawnSystem") {
+		t.Errorf("expected timing for spawnSystem, got %+v", stats.Systems)
+	}
+}
+
+func TestWorld_StatsCountChangesBetweenUpdates(t *testing.T) {
+	w := NewWorld()
+	w.AddSystem(&spawnSystem{count: 2})
+	w.Update(0.016)
+
+	outside := w.CreateEntity()
+	w.AddComponents(outside, CharacterComponent{})
+	w.DestroyEntity(outside)
+	if stats := w.Stats(); stats.StructuralChanges != (StructuralChanges{Created: 2, Moved: 2}) {
+		t.Errorf("expected the report to wait for the next Update, got %+v", stats.StructuralChanges)
+	}
+
+	w.Update(0.016)
+	expected := StructuralChanges{Created: 3, Destroyed: 1, Moved: 3}
+	if stats := w.Stats(); stats.StructuralChanges != expected {
+		t.Errorf("expected changes outside Update to land in the next report %+v, got %+v", expected, stats.StructuralChanges)
+	}
+	w.Update(0.016)
+	if stats := w.Stats(); stats.StructuralChanges != (StructuralChanges{Created: 2, Moved: 2}) {
+		t.Errorf("expected them to be reported once, got %+v", stats.StructuralChanges)
+	}
+}
@@ -1,6 +1,7 @@
 package lib
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -30,3 +31,258 @@ func TestWorld_AddComponent(t *testing.T) {
 
 	})
 }
+
+type handleComponent struct {
+	handle *int
+}
+
+type pathComponent struct {
+	points []float64
+}
+
+var released []EntityID
+
+var handleComponentID = RegisterComponent[handleComponent](
+	WithDefault(func() handleComponent { return handleComponent{handle: new(int)} }),
+	WithOnRemove(func(entity EntityID, _ handleComponent) { released = append(released, entity) }),
+)
+var pathComponentID = RegisterComponent[pathComponent](
+	WithClone(func(p pathComponent) pathComponent {
+		return pathComponent{points: append([]float64(nil), p.points...)}
+	}),
+)
+
+func TestWorld_OnRemoveHook(t *testing.T) {
+	w := NewWorld()
+	released = nil
+
+	a := w.CreateEntity()
+	b := w.CreateEntity()
+	w.AddDefaultComponents(a, handleComponentID)
+	w.AddComponents(b, handleComponent{}, PositionComponent{})
+
+	w.RemoveComponent(a, handleComponentID)
+	w.RemoveComponent(a, handleComponentID)
+	w.DestroyEntity(b)
+
+	if len(released) != 2 || released[0] != a || released[1] != b {
+		t.Errorf("expected OnRemove for %v and %v, got %v", a, b, released)
+	}
+}
+
+func TestWorld_OnRemoveHookRunsWhenOverwritten(t *testing.T) {
+	w := NewWorld()
+	released = nil
+
+	entity := w.CreateEntity()
+	w.AddComponents(entity, handleComponent{})
+	w.AddComponents(entity, handleComponent{}, PositionComponent{})
+	w.AddDefaultComponents(entity, handleComponentID)
+
+	if len(released) != 2 || released[0] != entity || released[1] != entity {
+		t.Errorf("expected OnRemove for both replaced values of %v, got %v", entity, released)
+	}
+}
+
+func TestWorld_AddDefaultComponents(t *testing.T) {
+	w := NewWorld()
+	entity := w.CreateEntity()
+	w.AddDefaultComponents(entity, handleComponentID, GetComponentID[PositionComponent]())
+
+	found := false
+	w.Query().With(handleComponentID).Each(func(id EntityID, m map[ComponentID]interface{}) {
+		found = id == entity && m[handleComponentID].(handleComponent).handle != nil
+	})
+	if !found {
+		t.Errorf("expected default handle component on entity %v", entity)
+	}
+}
+
+func TestWorld_CloneEntity(t *testing.T) {
+	w := NewWorld()
+	original := w.CreateEntity()
+	w.AddComponents(original, pathComponent{points: []float64{1, 2}}, PositionComponent{x: 3, y: 4})
+	w.DisableComponent(original, GetComponentID[PositionComponent]())
+
+	clone := w.CloneEntity(original)
+	if clone == original {
+		t.Fatalf("expected a new entity id")
+	}
+
+	paths := map[EntityID]pathComponent{}
+	w.Query().With(pathComponentID).Each(func(id EntityID, m map[ComponentID]interface{}) {
+		paths[id] = m[pathComponentID].(pathComponent)
+	})
+	if len(paths) != 2 {
+		t.Fatalf("expected 2 entities with paths, got %d", len(paths))
+	}
+	paths[clone].points[0] = 100
+	if paths[original].points[0] != 1 {
+		t.Errorf("expected clone hook to deep copy points")
+	}
+
+	positions := 0
+	w.Query().With(GetComponentID[PositionComponent]()).Each(func(id EntityID, m map[ComponentID]interface{}) {
+		positions++
+	})
+	if positions != 0 {
+		t.Errorf("expected disabled mask to be cloned, got %d enabled positions", positions)
+	}
+}
+
+type followComponent struct {
+	target EntityID
+}
+
+var followComponentID = RegisterComponent[followComponent](
+	WithEntityRefs(func(f followComponent, remap func(EntityID) EntityID) followComponent {
+		f.target = remap(f.target)
+		return f
+	}),
+)
+
+func followTargets(w *World) map[EntityID]EntityID {
+	targets := map[EntityID]EntityID{}
+	w.Query().With(followComponentID).Each(func(id EntityID, m map[ComponentID]interface{}) {
+		targets[id] = m[followComponentID].(followComponent).target
+	})
+	return targets
+}
+
+func TestWorld_CloneEntitiesRemapsReferences(t *testing.T) {
+	w := NewWorld()
+	leader := w.CreateEntity()
+	follower := w.CreateEntity()
+	outsider := w.CreateEntity()
+	w.AddComponents(leader, followComponent{target: outsider})
+	w.AddComponents(follower, followComponent{target: leader})
+
+	clones := w.CloneEntities(leader, follower)
+
+	targets := followTargets(w)
+	if targets[clones[1]] != clones[0] {
+		t.Errorf("expected cloned follower to follow cloned leader %v, got %v", clones[0], targets[clones[1]])
+	}
+	if targets[clones[0]] != outsider {
+		t.Errorf("expected reference outside the group to be kept, got %v", targets[clones[0]])
+	}
+	if targets[follower] != leader {
+		t.Errorf("expected original follower to be untouched, got %v", targets[follower])
+	}
+}
+
+func TestWorld_MoveEntitiesTo(t *testing.T) {
+	staging := NewWorld()
+	main := NewWorld()
+	main.CreateEntity()
+
+	leader := staging.CreateEntity()
+	follower := staging.CreateEntity()
+	staging.AddComponents(leader, followComponent{target: leader}, PositionComponent{x: 1})
+	staging.AddComponents(follower, followComponent{target: leader})
+	staging.DisableComponent(leader, GetComponentID[PositionComponent]())
+
+	released = nil
+	staging.AddComponents(follower, handleComponent{})
+	moved := staging.MoveEntitiesTo(main, leader, follower)
+
+	if staging.GetEntityCount() != 0 {
+		t.Errorf("expected staging world to be empty, got %d entities", staging.GetEntityCount())
+	}
+	if main.GetEntityCount() != 3 {
+		t.Errorf("expected 3 entities in main world, got %d", main.GetEntityCount())
+	}
+	if len(released) != 0 {
+		t.Errorf("expected no OnRemove calls when moving, got %v", released)
+	}
+
+	targets := followTargets(main)
+	if targets[moved[0]] != moved[0] || targets[moved[1]] != moved[0] {
+		t.Errorf("expected references to follow moved leader %v, got %v", moved[0], targets)
+	}
+
+	positions := 0
+	main.Query().With(GetComponentID[PositionComponent]()).Each(func(id EntityID, m map[ComponentID]interface{}) {
+		positions++
+	})
+	if positions != 0 {
+		t.Errorf("expected disabled mask to move with the entity, got %d enabled positions", positions)
+	}
+}
+
+func TestWorld_GroupsRejectDuplicateEntities(t *testing.T) {
+	staging := NewWorld()
+	main := NewWorld()
+	entity := staging.CreateEntity()
+	staging.AddComponents(entity, PositionComponent{x: 1})
+
+	if _, err := staging.TryCloneEntities(entity, entity); !errors.Is(err, ErrDuplicateEntity) {
+		t.Errorf("expected %v from cloning, got %v", ErrDuplicateEntity, err)
+	}
+	if _, err := staging.TryMoveEntitiesTo(main, entity, entity); !errors.Is(err, ErrDuplicateEntity) {
+		t.Errorf("expected %v from moving, got %v", ErrDuplicateEntity, err)
+	}
+	if staging.GetEntityCount() != 1 || main.GetEntityCount() != 0 {
+		t.Errorf("expected both worlds untouched, got %d and %d entities", staging.GetEntityCount(), main.GetEntityCount())
+	}
+}
+
+func TestWorld_TagComponentsHaveNoColumn(t *testing.T) {
+	w := NewWorld()
+	tagID := GetComponentID[IsEnabledComponent]()
+	entity := w.CreateEntity()
+	w.AddComponents(entity, CharacterComponent{name: "tagged"}, IsEnabledComponent{})
+	w.AddComponents(entity, IsEnabledComponent{})
+
+	archetype := w.archetypes[w.locations.at(int(entity)).archetype]
+	if _, exists := archetype.components[tagID]; exists {
+		t.Errorf("expected no column for tag component")
+	}
+	if !archetype.bitset.HasID(tagID) || !Has[IsEnabledComponent](w, entity) {
+		t.Errorf("expected tag to be recorded in the archetype bitset")
+	}
+
+	found := 0
+	w.Query().With(tagID).Each(func(id EntityID, m map[ComponentID]interface{}) {
+		if _, ok := m[tagID].(IsEnabledComponent); ok {
+			found++
+		}
+	})
+	if found != 1 {
+		t.Errorf("expected the tag's zero value in query results, found %d", found)
+	}
+
+	clone := w.CloneEntity(entity)
+	w.RemoveComponent(entity, tagID)
+	if Has[IsEnabledComponent](w, entity) || !Has[IsEnabledComponent](w, clone) {
+		t.Errorf("expected the tag to be removed from the original and kept on the clone")
+	}
+	if character, _ := Get[CharacterComponent](w, entity); character.name != "tagged" {
+		t.Errorf("expected other components to survive tag removal, got %v", character)
+	}
+}
+
+func TestWorld_EntityChurnReusesIDs(t *testing.T) {
+	w := NewWorld()
+	const live = 100
+	for round := 0; round < 200; round++ {
+		entities := make([]EntityID, live)
+		for i := range entities {
+			entities[i] = w.CreateEntity()
+			w.AddComponents(entities[i], PositionComponent{x: float64(i)}, selectedComponent{Order: i})
+		}
+		for _, entity := range entities {
+			w.DestroyEntity(entity)
+		}
+	}
+
+	if w.GetEntityCount() != 0 {
+		t.Errorf("expected no live entities, got %d", w.GetEntityCount())
+	}
+	if w.locations.len() != live {
+		t.Errorf("expected location slots bounded by the %d live at once, got %d", live, w.locations.len())
+	}
+	if rows := w.sparseSets[selectedComponentID].rows.len(); rows > live {
+		t.Errorf("expected sparse rows bounded by %d, got %d", live, rows)
+	}
+}
@@ -0,0 +1,63 @@
+// Package render draws the demo through a Backend, so render systems can be
+// tested against RecordingBackend without a GPU. The raylib implementation
+// lives in render/raylib.
+package render
+
+type Color struct {
+	R, G, B, A uint8
+}
+
+var (
+	White    = Color{R: 255, G: 255, B: 255, A: 255}
+	Black    = Color{R: 0, G: 0, B: 0, A: 255}
+	Red      = Color{R: 230, G: 41, B: 55, A: 255}
+	RayWhite = Color{R: 245, G: 245, B: 245, A: 255}
+)
+
+type Vector2 struct {
+	X, Y float32
+}
+
+type Rectangle struct {
+	X, Y, Width, Height float32
+}
+
+// Target is an offscreen render texture owned by a Backend.
+type Target struct {
+	ID            uint32
+	Width, Height int32
+}
+
+// Shader is a fragment shader owned by a Backend.
+type Shader struct {
+	ID   uint32
+	Path string
+}
+
+// Backend is the set of drawing operations the demo needs. Calls between
+// BeginTarget and EndTarget draw into the target, otherwise to the screen;
+// calls between BeginShader and EndShader go through the shader.
+type Backend interface {
+	BeginFrame()
+	EndFrame()
+	Clear(color Color)
+
+	DrawCircle(center Vector2, radius float32, color Color)
+	DrawLine(from, to Vector2, color Color)
+	DrawText(text string, x, y, fontSize int32, color Color)
+	// DrawTarget stretches a target's texture over dst.
+	DrawTarget(target Target, dst Rectangle, tint Color)
+
+	LoadTarget(width, height int32) Target
+	UnloadTarget(target Target)
+	BeginTarget(target Target)
+	EndTarget()
+
+	LoadShader(path string) Shader
+	UnloadShader(shader Shader)
+	// SetUniform sets a float, vec2, vec3 or vec4 uniform depending on how
+	// many values are given.
+	SetUniform(shader Shader, name string, values ...float32)
+	BeginShader(shader Shader)
+	EndShader()
+}
@@ -0,0 +1,55 @@
+package render
+
+import (
+	"testing"
+
+	"ecs/components"
+	"ecs/lib"
+	"ecs/lib/replay"
+)
+
+type fakeWindow struct{}
+
+func (fakeWindow) RenderSize() (int32, int32) {
+	return 1600, 900
+}
+
+func (fakeWindow) ScreenSize() (int32, int32) {
+	return 800, 450
+}
+
+type fakeInput struct{}
+
+func (fakeInput) Current() replay.Input {
+	return replay.Input{MouseX: 400, MouseY: 300}
+}
+
+func newRenderWorld() *lib.World {
+	world := lib.NewWorld()
+	world.AddComponents(world.CreateEntity(),
+		components.ParticleComponent{Radius: 4},
+		components.PositionComponent{X: 10, Y: 20},
+		components.ColorComponent{R: 255, G: 128, A: 200},
+		components.VisibleComponent{},
+	)
+	world.AddComponents(world.CreateEntity(),
+		components.ParticleComponent{Radius: 2},
+		components.PositionComponent{X: 30, Y: 40},
+		components.ColorComponent{R: 255, A: 255},
+	)
+	world.AddComponents(world.CreateEntity(),
+		components.TextComponent{Text: "Entities: 3", FontSize: 14},
+		components.PositionComponent{X: 10, Y: 50},
+		components.VisibleComponent{},
+	)
+	return world
+}
+
+func newDemoChain(t *testing.T, backend Backend) *PostProcessChain {
+	t.Helper()
+	chain, err := LoadPostProcessChain(backend, "../shaders/postprocess.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return chain
+}
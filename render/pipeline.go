@@ -0,0 +1,97 @@
+package render
+
+import (
+	"math"
+
+	"ecs/lib"
+	"ecs/systems"
+)
+
+// Window reports the sizes a frame is drawn at: RenderSize in pixels for
+// offscreen targets, ScreenSize in logical units for the final blit.
+type Window interface {
+	RenderSize() (width, height int32)
+	ScreenSize() (width, height int32)
+}
+
+// Pipeline draws one demo frame: particles into a target, then through the
+// post-processing chain onto the screen, with the HUD drawn before the last
+// pass. It is a lib.RenderSystem.
+type Pipeline struct {
+	backend   Backend
+	window    Window
+	clock     systems.Clock
+	input     systems.Input
+	particles *ParticleRenderer
+	text      *TextRenderer
+	targets   *TargetPool
+	chain     *PostProcessChain
+}
+
+// NewPipeline takes ownership of chain and feeds its "mouse_distance" and
+// "frame_time" uniform sources; a chain reading any other source is an error.
+func NewPipeline(backend Backend, window Window, clock systems.Clock, input systems.Input, chain *PostProcessChain) (*Pipeline, error) {
+	p := &Pipeline{
+		backend:   backend,
+		window:    window,
+		clock:     clock,
+		input:     input,
+		particles: NewParticleRenderer(backend, input),
+		text:      NewTextRenderer(backend),
+		targets:   NewTargetPool(backend, 2),
+		chain:     chain,
+	}
+	chain.SetSource("mouse_distance", p.mouseDistance)
+	chain.SetSource("frame_time", func() []float32 { return []float32{p.clock.FrameTime()} })
+	if err := chain.CheckSources(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *Pipeline) Render(world *lib.World, alpha float64) {
+	width, height := p.window.RenderSize()
+	screenWidth, screenHeight := p.window.ScreenSize()
+
+	// The frame still begins and ends with nothing to draw into, as the
+	// backend handles window events there.
+	targets := p.targets.Frame(width, height)
+	p.backend.BeginFrame()
+	defer p.backend.EndFrame()
+	if targets == nil {
+		return
+	}
+	p.backend.BeginTarget(targets[0])
+	p.backend.Clear(RayWhite)
+	p.particles.Render(world, alpha)
+	p.backend.EndTarget()
+
+	screen := Rectangle{Width: float32(screenWidth), Height: float32(screenHeight)}
+	p.chain.Apply(targets[0], targets[1], screen, func() { p.text.Render(world, alpha) })
+}
+
+// Chain exposes the pipeline's passes so they can be toggled at runtime.
+func (p *Pipeline) Chain() *PostProcessChain {
+	return p.chain
+}
+
+// Targets exposes the pipeline's target pool, e.g. to check Allocations.
+func (p *Pipeline) Targets() *TargetPool {
+	return p.targets
+}
+
+func (p *Pipeline) Close() {
+	p.targets.Close()
+	p.chain.Close()
+}
+
+func (p *Pipeline) mouseDistance() []float32 {
+	width, height := p.window.RenderSize()
+	input := p.input.Current()
+	middleX, middleY := float32(width)/2, float32(height/2)
+	return []float32{distance(middleX, middleY, float32(input.MouseX), float32(input.MouseY)) / float32(width)}
+}
+
+func distance(x1, y1, x2, y2 float32) float32 {
+	return float32(math.Hypot(float64(x2-x1), float64(y2-y1)))
+}
@@ -0,0 +1,60 @@
+package render
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ecs/systems"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(expected) {
+		t.Errorf("%s mismatch (run with -update to accept):\n%s", name, got)
+	}
+}
+
+func TestPipeline_Frame(t *testing.T) {
+	backend := NewRecordingBackend()
+	pipeline, err := NewPipeline(backend, fakeWindow{}, systems.FixedClock{Step: 0.5}, fakeInput{}, newDemoChain(t, backend))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipeline.Render(newRenderWorld(), 0)
+	pipeline.Close()
+
+	assertGolden(t, "frame.golden", backend.String())
+}
+
+func TestParticleRenderer(t *testing.T) {
+	backend := NewRecordingBackend()
+	NewParticleRenderer(backend, fakeInput{}).Render(newRenderWorld(), 0)
+
+	expected := []Call{
+		{Name: "DrawCircle", Args: []interface{}{Vector2{X: 10, Y: 20}, float32(4), Color{R: 255, G: 128, A: 200}}},
+		{Name: "DrawLine", Args: []interface{}{Vector2{X: 10, Y: 20}, Vector2{X: 400, Y: 300}, Color{R: 230, G: 41, B: 55, A: 20}}},
+	}
+	calls := backend.Calls()
+	if len(calls) != len(expected) {
+		t.Fatalf("expected only the visible particle to be drawn, got:\n%s", backend)
+	}
+	for i := range expected {
+		if calls[i].String() != expected[i].String() {
+			t.Errorf("call %d: expected %s, got %s", i, expected[i], calls[i])
+		}
+	}
+}
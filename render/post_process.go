@@ -0,0 +1,262 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+var ErrUnknownPass = errors.New("unknown post-processing pass")
+
+// PostProcessConfig is the file format of a post-processing chain, e.g.
+// shaders/postprocess.json.
+type PostProcessConfig struct {
+	Passes []PassConfig `json:"passes"`
+}
+
+type PassConfig struct {
+	Name string `json:"name"`
+	// Shader is a fragment shader path, relative to the config file.
+	Shader   string                   `json:"shader"`
+	Disabled bool                     `json:"disabled,omitempty"`
+	Uniforms map[string]UniformConfig `json:"uniforms,omitempty"`
+}
+
+// UniformConfig declares a uniform the pass sets before drawing: either a
+// fixed Value, or a Source name registered on the chain with SetSource and
+// read every frame.
+type UniformConfig struct {
+	Value  []float32 `json:"value,omitempty"`
+	Source string    `json:"source,omitempty"`
+}
+
+// PostProcessChain runs a scene through an ordered list of shader passes,
+// ping-ponging between two targets. The last enabled pass draws to the
+// screen. Passes can be enabled, disabled and reordered between frames.
+type PostProcessChain struct {
+	backend Backend
+	passes  []*postProcessPass
+	sources map[string]func() []float32
+}
+
+type postProcessPass struct {
+	config  PassConfig
+	shader  Shader
+	enabled bool
+	values  map[string][]float32
+	sources map[string]string
+}
+
+// LoadPostProcessChain reads a chain config file and loads its shaders.
+func LoadPostProcessChain(backend Backend, configPath string) (*PostProcessChain, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	var config PostProcessConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", configPath, err)
+	}
+	return NewPostProcessChain(backend, config, filepath.Dir(configPath))
+}
+
+// NewPostProcessChain checks the config, including that every shader file
+// exists, before loading any shader. Uniform sources are checked separately
+// by CheckSources once they have been registered.
+func NewPostProcessChain(backend Backend, config PostProcessConfig, shaderDir string) (*PostProcessChain, error) {
+	chain := &PostProcessChain{backend: backend, sources: make(map[string]func() []float32)}
+	for _, passConfig := range config.Passes {
+		if passConfig.Name == "" || passConfig.Shader == "" {
+			return nil, fmt.Errorf("post-processing pass %q needs a name and a shader", passConfig.Name)
+		}
+		if chain.pass(passConfig.Name) != nil {
+			return nil, fmt.Errorf("duplicate post-processing pass %q", passConfig.Name)
+		}
+		pass := &postProcessPass{
+			config:  passConfig,
+			enabled: !passConfig.Disabled,
+			values:  make(map[string][]float32),
+			sources: make(map[string]string),
+		}
+		for name, uniform := range passConfig.Uniforms {
+			if (uniform.Value == nil) == (uniform.Source == "") {
+				return nil, fmt.Errorf("uniform %s of pass %q needs either a value or a source", name, passConfig.Name)
+			}
+			if uniform.Value != nil {
+				if err := checkUniformSize(uniform.Value); err != nil {
+					return nil, fmt.Errorf("uniform %s of pass %q: %w", name, passConfig.Name, err)
+				}
+			}
+			pass.values[name] = uniform.Value
+			if uniform.Source != "" {
+				pass.sources[name] = uniform.Source
+			}
+		}
+		chain.passes = append(chain.passes, pass)
+	}
+	shaderPaths := make([]string, len(chain.passes))
+	for i, pass := range chain.passes {
+		shaderPaths[i] = filepath.Join(shaderDir, pass.config.Shader)
+		if _, err := os.Stat(shaderPaths[i]); err != nil {
+			return nil, fmt.Errorf("shader of pass %q: %w", pass.config.Name, err)
+		}
+	}
+	for i, pass := range chain.passes {
+		pass.shader = backend.LoadShader(shaderPaths[i])
+	}
+	return chain, nil
+}
+
+// CheckSources reports a uniform whose source has not been registered with
+// SetSource.
+func (c *PostProcessChain) CheckSources() error {
+	for _, pass := range c.passes {
+		for _, name := range slices.Sorted(maps.Keys(pass.sources)) {
+			if _, exists := c.sources[pass.sources[name]]; !exists {
+				return fmt.Errorf("uniform %s of pass %q reads unknown source %q", name, pass.config.Name, pass.sources[name])
+			}
+		}
+	}
+	return nil
+}
+
+// SetSource provides the values of every uniform declared with this source.
+func (c *PostProcessChain) SetSource(name string, values func() []float32) {
+	c.sources[name] = values
+}
+
+// SetUniform overrides a declared uniform of a pass until it is set again.
+func (c *PostProcessChain) SetUniform(passName, uniform string, values ...float32) error {
+	pass := c.pass(passName)
+	if pass == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownPass, passName)
+	}
+	if _, declared := pass.values[uniform]; !declared {
+		return fmt.Errorf("pass %s does not declare uniform %s", passName, uniform)
+	}
+	if err := checkUniformSize(values); err != nil {
+		return fmt.Errorf("uniform %s of pass %s: %w", uniform, passName, err)
+	}
+	pass.values[uniform] = values
+	delete(pass.sources, uniform)
+	return nil
+}
+
+func (c *PostProcessChain) SetEnabled(passName string, enabled bool) error {
+	pass := c.pass(passName)
+	if pass == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownPass, passName)
+	}
+	pass.enabled = enabled
+	return nil
+}
+
+func (c *PostProcessChain) Enabled(passName string) bool {
+	pass := c.pass(passName)
+	return pass != nil && pass.enabled
+}
+
+// Move puts a pass at index in the chain, shifting the others along.
+func (c *PostProcessChain) Move(passName string, index int) error {
+	i := slices.IndexFunc(c.passes, func(pass *postProcessPass) bool { return pass.config.Name == passName })
+	if i == -1 {
+		return fmt.Errorf("%w: %s", ErrUnknownPass, passName)
+	}
+	if index < 0 || index >= len(c.passes) {
+		return fmt.Errorf("pass index %d out of range", index)
+	}
+	pass := c.passes[i]
+	c.passes = slices.Insert(slices.Delete(c.passes, i, i+1), index, pass)
+	return nil
+}
+
+// Passes lists pass names in the order they run, including disabled ones.
+func (c *PostProcessChain) Passes() []string {
+	names := make([]string, len(c.passes))
+	for i, pass := range c.passes {
+		names[i] = pass.config.Name
+	}
+	return names
+}
+
+// Apply runs the enabled passes over scene, using spare as the other half of
+// the ping-pong pair, and draws the result into dst on the screen. overlay
+// is drawn into the intermediate image right before the last pass. Every
+// uniform source must be registered; see CheckSources.
+func (c *PostProcessChain) Apply(scene, spare Target, dst Rectangle, overlay func()) {
+	enabled := make([]*postProcessPass, 0, len(c.passes))
+	for _, pass := range c.passes {
+		if pass.enabled {
+			enabled = append(enabled, pass)
+		}
+	}
+
+	// Every pass but the last draws into the other target; the overlay goes
+	// on top of whatever the last of them produced.
+	last := len(enabled) - 1
+	current, next := scene, spare
+	for i := 0; i < last; i++ {
+		c.backend.BeginTarget(next)
+		c.draw(enabled[i], current, Rectangle{Width: float32(next.Width), Height: float32(next.Height)})
+		if i == last-1 && overlay != nil {
+			overlay()
+		}
+		c.backend.EndTarget()
+		current, next = next, current
+	}
+	if last <= 0 && overlay != nil {
+		c.backend.BeginTarget(current)
+		overlay()
+		c.backend.EndTarget()
+	}
+
+	if last == -1 {
+		c.backend.DrawTarget(current, dst, White)
+		return
+	}
+	c.draw(enabled[last], current, dst)
+}
+
+func (c *PostProcessChain) Close() {
+	for _, pass := range c.passes {
+		c.backend.UnloadShader(pass.shader)
+	}
+}
+
+func (c *PostProcessChain) draw(pass *postProcessPass, source Target, dst Rectangle) {
+	for _, name := range slices.Sorted(maps.Keys(pass.values)) {
+		values := pass.values[name]
+		if source, fromSource := pass.sources[name]; fromSource {
+			read, exists := c.sources[source]
+			if !exists {
+				panic(fmt.Sprintf("post-processing source %q is not registered; see CheckSources", source))
+			}
+			values = read()
+		}
+		c.backend.SetUniform(pass.shader, name, values...)
+	}
+	c.backend.BeginShader(pass.shader)
+	c.backend.DrawTarget(source, dst, White)
+	c.backend.EndShader()
+}
+
+func (c *PostProcessChain) pass(name string) *postProcessPass {
+	for _, pass := range c.passes {
+		if pass.config.Name == name {
+			return pass
+		}
+	}
+	return nil
+}
+
+// checkUniformSize accepts a float, vec2, vec3 or vec4.
+func checkUniformSize(values []float32) error {
+	if len(values) < 1 || len(values) > 4 {
+		return fmt.Errorf("expected 1 to 4 values, got %d", len(values))
+	}
+	return nil
+}
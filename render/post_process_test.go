@@ -0,0 +1,215 @@
+package render
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ecs/systems"
+)
+
+var threePasses = PostProcessConfig{Passes: []PassConfig{
+	{Name: "blur", Shader: "blur.glsl", Uniforms: map[string]UniformConfig{"radius": {Value: []float32{2}}}},
+	{Name: "bloom", Shader: "bloom.glsl", Uniforms: map[string]UniformConfig{"threshold": {Source: "threshold"}}},
+	{Name: "vignette", Shader: "vignette.glsl", Disabled: true},
+}}
+
+func newTestChain(t *testing.T, backend Backend) *PostProcessChain {
+	t.Helper()
+	chain, err := NewPostProcessChain(backend, threePasses, "testdata/fx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.SetSource("threshold", func() []float32 { return []float32{0.5} })
+	return chain
+}
+
+// applyCalls runs one Apply and returns its calls, leaving out the ones that
+// only depend on the uniforms.
+func applyCalls(backend *RecordingBackend, chain *PostProcessChain) string {
+	scene := Target{ID: 1, Width: 100, Height: 50}
+	spare := Target{ID: 2, Width: 100, Height: 50}
+	backend.Reset()
+	chain.Apply(scene, spare, Rectangle{Width: 50, Height: 25}, func() { backend.DrawText("hud", 0, 0, 10, Black) })
+
+	var calls []string
+	for _, call := range backend.Calls() {
+		if call.Name != "SetUniform" && call.Name != "EndShader" {
+			calls = append(calls, call.String())
+		}
+	}
+	return strings.Join(calls, "\n")
+}
+
+func TestPostProcessChain_PingPong(t *testing.T) {
+	backend := NewRecordingBackend()
+	chain := newTestChain(t, backend)
+	if err := chain.SetEnabled("vignette", true); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Join([]string{
+		"BeginTarget {2 100 50}",
+		"BeginShader {1 testdata/fx/blur.glsl}",
+		"DrawTarget {1 100 50} {0 0 100 50} {255 255 255 255}",
+		"EndTarget",
+		"BeginTarget {1 100 50}",
+		"BeginShader {2 testdata/fx/bloom.glsl}",
+		"DrawTarget {2 100 50} {0 0 100 50} {255 255 255 255}",
+		`DrawText "hud" 0 0 10 {0 0 0 255}`,
+		"EndTarget",
+		"BeginShader {3 testdata/fx/vignette.glsl}",
+		"DrawTarget {1 100 50} {0 0 50 25} {255 255 255 255}",
+	}, "\n")
+	if got := applyCalls(backend, chain); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestPostProcessChain_EnableAndReorder(t *testing.T) {
+	backend := NewRecordingBackend()
+	chain := newTestChain(t, backend)
+
+	if err := chain.Move("bloom", 0); err != nil {
+		t.Fatal(err)
+	}
+	if order := chain.Passes(); !reflect.DeepEqual(order, []string{"bloom", "blur", "vignette"}) {
+		t.Errorf("unexpected order after move: %v", order)
+	}
+	expected := strings.Join([]string{
+		"BeginTarget {2 100 50}",
+		"BeginShader {2 testdata/fx/bloom.glsl}",
+		"DrawTarget {1 100 50} {0 0 100 50} {255 255 255 255}",
+		`DrawText "hud" 0 0 10 {0 0 0 255}`,
+		"EndTarget",
+		"BeginShader {1 testdata/fx/blur.glsl}",
+		"DrawTarget {2 100 50} {0 0 50 25} {255 255 255 255}",
+	}, "\n")
+	if got := applyCalls(backend, chain); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	chain.SetEnabled("bloom", false)
+	chain.SetEnabled("blur", false)
+	expected = strings.Join([]string{
+		"BeginTarget {1 100 50}",
+		`DrawText "hud" 0 0 10 {0 0 0 255}`,
+		"EndTarget",
+		"DrawTarget {1 100 50} {0 0 50 25} {255 255 255 255}",
+	}, "\n")
+	if got := applyCalls(backend, chain); got != expected {
+		t.Errorf("expected a plain blit with every pass disabled:\n%s\ngot:\n%s", expected, got)
+	}
+
+	if err := chain.Move("bloom", 3); err == nil {
+		t.Error("expected error moving past the end of the chain")
+	}
+	if err := chain.SetEnabled("sharpen", true); !errors.Is(err, ErrUnknownPass) {
+		t.Errorf("expected ErrUnknownPass, got %v", err)
+	}
+}
+
+func TestPostProcessChain_Uniforms(t *testing.T) {
+	backend := NewRecordingBackend()
+	chain := newTestChain(t, backend)
+	threshold := float32(0.5)
+	chain.SetSource("threshold", func() []float32 { return []float32{threshold} })
+
+	uniforms := func() []string {
+		applyCalls(backend, chain)
+		var calls []string
+		for _, call := range backend.Calls() {
+			if call.Name == "SetUniform" {
+				calls = append(calls, call.String())
+			}
+		}
+		return calls
+	}
+
+	expected := []string{"SetUniform {1 testdata/fx/blur.glsl} radius [2]", "SetUniform {2 testdata/fx/bloom.glsl} threshold [0.5]"}
+	if got := uniforms(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	threshold = 0.75
+	if err := chain.SetUniform("blur", "radius", 4); err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"SetUniform {1 testdata/fx/blur.glsl} radius [4]", "SetUniform {2 testdata/fx/bloom.glsl} threshold [0.75]"}
+	if got := uniforms(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	if err := chain.SetUniform("blur", "sigma", 1); err == nil {
+		t.Error("expected error setting an undeclared uniform")
+	}
+	if err := chain.SetUniform("blur", "radius", 1, 2, 3, 4, 5); err == nil {
+		t.Error("expected error setting more values than a vec4")
+	}
+	if err := chain.SetUniform("sharpen", "amount", 1); !errors.Is(err, ErrUnknownPass) {
+		t.Errorf("expected ErrUnknownPass, got %v", err)
+	}
+}
+
+func TestNewPostProcessChain_InvalidConfig(t *testing.T) {
+	configs := map[string]PostProcessConfig{
+		"duplicate name": {Passes: []PassConfig{{Name: "blur", Shader: "a.glsl"}, {Name: "blur", Shader: "b.glsl"}}},
+		"missing shader": {Passes: []PassConfig{{Name: "blur"}}},
+		"value and source": {Passes: []PassConfig{{Name: "blur", Shader: "blur.glsl", Uniforms: map[string]UniformConfig{
+			"radius": {Value: []float32{1}, Source: "radius"},
+		}}}},
+		"empty uniform": {Passes: []PassConfig{{Name: "blur", Shader: "blur.glsl", Uniforms: map[string]UniformConfig{"radius": {}}}}},
+		"empty value": {Passes: []PassConfig{{Name: "blur", Shader: "blur.glsl", Uniforms: map[string]UniformConfig{
+			"radius": {Value: []float32{}},
+		}}}},
+		"value too long": {Passes: []PassConfig{{Name: "blur", Shader: "blur.glsl", Uniforms: map[string]UniformConfig{
+			"radius": {Value: []float32{1, 2, 3, 4, 5}},
+		}}}},
+		"missing shader file": {Passes: []PassConfig{{Name: "blur", Shader: "blur.glsl"}, {Name: "sharpen", Shader: "sharpen.glsl"}}},
+	}
+	for name, config := range configs {
+		backend := NewRecordingBackend()
+		if _, err := NewPostProcessChain(backend, config, "testdata/fx"); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+		if len(backend.Calls()) != 0 {
+			t.Errorf("%s: expected no shaders to be loaded, got:\n%s", name, backend)
+		}
+	}
+}
+
+func TestLoadPostProcessChain(t *testing.T) {
+	backend := NewRecordingBackend()
+	chain := newDemoChain(t, backend)
+	if order := chain.Passes(); !reflect.DeepEqual(order, []string{"fisheye", "grading"}) {
+		t.Errorf("unexpected passes: %v", order)
+	}
+	chain.Close()
+	if unloads := countCalls(backend, "UnloadShader"); unloads != 2 {
+		t.Errorf("expected both shaders to be unloaded, got %d unloads", unloads)
+	}
+
+	if _, err := LoadPostProcessChain(backend, "testdata/missing.json"); err == nil {
+		t.Error("expected error loading a missing file")
+	}
+}
+
+func TestPostProcessChain_CheckSources(t *testing.T) {
+	chain, err := NewPostProcessChain(NewRecordingBackend(), threePasses, "testdata/fx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.CheckSources(); err == nil || !strings.Contains(err.Error(), `"threshold"`) {
+		t.Errorf("expected the unregistered source to be reported, got %v", err)
+	}
+	chain.SetSource("threshold", func() []float32 { return []float32{1} })
+	if err := chain.CheckSources(); err != nil {
+		t.Error(err)
+	}
+
+	chain, _ = NewPostProcessChain(NewRecordingBackend(), threePasses, "testdata/fx")
+	if _, err := NewPipeline(NewRecordingBackend(), fakeWindow{}, systems.FixedClock{}, fakeInput{}, chain); err == nil {
+		t.Error("expected the pipeline to reject a chain reading a source it doesn't provide")
+	}
+}
@@ -0,0 +1,128 @@
+// Package raylib implements render.Backend on top of raylib. It is the only
+// render package that links raylib, so everything else tests without cgo.
+package raylib
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+
+	"ecs/render"
+)
+
+// Backend draws with raylib. The window must already be open.
+type Backend struct {
+	nextID    uint32
+	targets   map[uint32]rl.RenderTexture2D
+	shaders   map[uint32]rl.Shader
+	locations map[uint32]map[string]int32
+}
+
+func New() *Backend {
+	return &Backend{
+		targets:   make(map[uint32]rl.RenderTexture2D),
+		shaders:   make(map[uint32]rl.Shader),
+		locations: make(map[uint32]map[string]int32),
+	}
+}
+
+func color(c render.Color) rl.Color {
+	return rl.NewColor(c.R, c.G, c.B, c.A)
+}
+
+func vector(v render.Vector2) rl.Vector2 {
+	return rl.Vector2{X: v.X, Y: v.Y}
+}
+
+func (b *Backend) BeginFrame() {
+	rl.BeginDrawing()
+}
+
+func (b *Backend) EndFrame() {
+	rl.EndDrawing()
+}
+
+func (b *Backend) Clear(c render.Color) {
+	rl.ClearBackground(color(c))
+}
+
+func (b *Backend) DrawCircle(center render.Vector2, radius float32, c render.Color) {
+	rl.DrawCircleV(vector(center), radius, color(c))
+}
+
+func (b *Backend) DrawLine(from, to render.Vector2, c render.Color) {
+	rl.DrawLineV(vector(from), vector(to), color(c))
+}
+
+func (b *Backend) DrawText(text string, x, y, fontSize int32, c render.Color) {
+	rl.DrawText(text, x, y, fontSize, color(c))
+}
+
+// DrawTarget flips the texture vertically, since render textures are stored
+// upside down.
+func (b *Backend) DrawTarget(target render.Target, dst render.Rectangle, tint render.Color) {
+	texture := b.targets[target.ID].Texture
+	rl.DrawTexturePro(texture,
+		rl.NewRectangle(0, 0, float32(texture.Width), float32(-texture.Height)),
+		rl.NewRectangle(dst.X, dst.Y, dst.Width, dst.Height),
+		rl.NewVector2(0, 0), 0, color(tint),
+	)
+}
+
+func (b *Backend) LoadTarget(width, height int32) render.Target {
+	b.nextID++
+	b.targets[b.nextID] = rl.LoadRenderTexture(width, height)
+	return render.Target{ID: b.nextID, Width: width, Height: height}
+}
+
+func (b *Backend) UnloadTarget(target render.Target) {
+	rl.UnloadRenderTexture(b.targets[target.ID])
+	delete(b.targets, target.ID)
+}
+
+func (b *Backend) BeginTarget(target render.Target) {
+	rl.BeginTextureMode(b.targets[target.ID])
+}
+
+func (b *Backend) EndTarget() {
+	rl.EndTextureMode()
+}
+
+func (b *Backend) LoadShader(path string) render.Shader {
+	b.nextID++
+	b.shaders[b.nextID] = rl.LoadShader("", path)
+	b.locations[b.nextID] = make(map[string]int32)
+	return render.Shader{ID: b.nextID, Path: path}
+}
+
+func (b *Backend) UnloadShader(shader render.Shader) {
+	rl.UnloadShader(b.shaders[shader.ID])
+	delete(b.shaders, shader.ID)
+	delete(b.locations, shader.ID)
+}
+
+var uniformTypes = map[int]rl.ShaderUniformDataType{
+	1: rl.ShaderUniformFloat,
+	2: rl.ShaderUniformVec2,
+	3: rl.ShaderUniformVec3,
+	4: rl.ShaderUniformVec4,
+}
+
+func (b *Backend) SetUniform(shader render.Shader, name string, values ...float32) {
+	uniformType, valid := uniformTypes[len(values)]
+	if !valid {
+		return
+	}
+	location, cached := b.locations[shader.ID][name]
+	if !cached {
+		location = rl.GetShaderLocation(b.shaders[shader.ID], name)
+		b.locations[shader.ID][name] = location
+	}
+	rl.SetShaderValue(b.shaders[shader.ID], location, values, uniformType)
+}
+
+func (b *Backend) BeginShader(shader render.Shader) {
+	rl.BeginShaderMode(b.shaders[shader.ID])
+}
+
+func (b *Backend) EndShader() {
+	rl.EndShaderMode()
+}
@@ -0,0 +1,124 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Call is one recorded Backend call.
+type Call struct {
+	Name string
+	Args []interface{}
+}
+
+func (c Call) String() string {
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = fmt.Sprint(arg)
+	}
+	return strings.TrimSpace(c.Name + " " + strings.Join(args, " "))
+}
+
+// RecordingBackend draws nothing and keeps every call in order, for
+// golden-file tests of render systems.
+type RecordingBackend struct {
+	calls      []Call
+	nextTarget uint32
+	nextShader uint32
+}
+
+func NewRecordingBackend() *RecordingBackend {
+	return &RecordingBackend{}
+}
+
+func (b *RecordingBackend) Calls() []Call {
+	return b.calls
+}
+
+func (b *RecordingBackend) Reset() {
+	b.calls = nil
+}
+
+// String lists the calls one per line.
+func (b *RecordingBackend) String() string {
+	var s strings.Builder
+	for _, call := range b.calls {
+		s.WriteString(call.String())
+		s.WriteByte('\n')
+	}
+	return s.String()
+}
+
+func (b *RecordingBackend) record(name string, args ...interface{}) {
+	b.calls = append(b.calls, Call{Name: name, Args: args})
+}
+
+func (b *RecordingBackend) BeginFrame() {
+	b.record("BeginFrame")
+}
+
+func (b *RecordingBackend) EndFrame() {
+	b.record("EndFrame")
+}
+
+func (b *RecordingBackend) Clear(color Color) {
+	b.record("Clear", color)
+}
+
+func (b *RecordingBackend) DrawCircle(center Vector2, radius float32, color Color) {
+	b.record("DrawCircle", center, radius, color)
+}
+
+func (b *RecordingBackend) DrawLine(from, to Vector2, color Color) {
+	b.record("DrawLine", from, to, color)
+}
+
+func (b *RecordingBackend) DrawText(text string, x, y, fontSize int32, color Color) {
+	b.record("DrawText", fmt.Sprintf("%q", text), x, y, fontSize, color)
+}
+
+func (b *RecordingBackend) DrawTarget(target Target, dst Rectangle, tint Color) {
+	b.record("DrawTarget", target, dst, tint)
+}
+
+func (b *RecordingBackend) LoadTarget(width, height int32) Target {
+	b.nextTarget++
+	target := Target{ID: b.nextTarget, Width: width, Height: height}
+	b.record("LoadTarget", target)
+	return target
+}
+
+func (b *RecordingBackend) UnloadTarget(target Target) {
+	b.record("UnloadTarget", target)
+}
+
+func (b *RecordingBackend) BeginTarget(target Target) {
+	b.record("BeginTarget", target)
+}
+
+func (b *RecordingBackend) EndTarget() {
+	b.record("EndTarget")
+}
+
+func (b *RecordingBackend) LoadShader(path string) Shader {
+	b.nextShader++
+	shader := Shader{ID: b.nextShader, Path: path}
+	b.record("LoadShader", shader)
+	return shader
+}
+
+func (b *RecordingBackend) UnloadShader(shader Shader) {
+	b.record("UnloadShader", shader)
+}
+
+func (b *RecordingBackend) SetUniform(shader Shader, name string, values ...float32) {
+	b.record("SetUniform", shader, name, values)
+}
+
+func (b *RecordingBackend) BeginShader(shader Shader) {
+	b.record("BeginShader", shader)
+}
+
+func (b *RecordingBackend) EndShader() {
+	b.record("EndShader")
+}
@@ -0,0 +1,56 @@
+package render
+
+import (
+	"ecs/components"
+	"ecs/lib"
+	"ecs/systems"
+)
+
+// ParticleRenderer draws every visible particle as a circle with a faint line
+// to the mouse.
+type ParticleRenderer struct {
+	backend Backend
+	input   systems.Input
+}
+
+func NewParticleRenderer(backend Backend, input systems.Input) *ParticleRenderer {
+	return &ParticleRenderer{backend: backend, input: input}
+}
+
+func (r *ParticleRenderer) Render(world *lib.World, _ float64) {
+	input := r.input.Current()
+	mouse := Vector2{X: float32(input.MouseX), Y: float32(input.MouseY)}
+	world.Query().
+		With(components.ParticleComponentID, components.PositionComponentID, components.ColorComponentID, components.VisibleComponentID).
+		Each(func(id lib.EntityID, values map[lib.ComponentID]interface{}) {
+			particle := values[components.ParticleComponentID].(components.ParticleComponent)
+			position := values[components.PositionComponentID].(components.PositionComponent)
+			color := Color(values[components.ColorComponentID].(components.ColorComponent))
+
+			center := Vector2{X: float32(position.X), Y: float32(position.Y)}
+			r.backend.DrawCircle(center, particle.Radius, color)
+
+			line := Red
+			line.A = color.A / 10
+			r.backend.DrawLine(center, mouse, line)
+		})
+}
+
+// TextRenderer draws every visible text label.
+type TextRenderer struct {
+	backend Backend
+}
+
+func NewTextRenderer(backend Backend) *TextRenderer {
+	return &TextRenderer{backend: backend}
+}
+
+func (r *TextRenderer) Render(world *lib.World, _ float64) {
+	world.Query().
+		With(components.TextComponentID, components.PositionComponentID, components.VisibleComponentID).
+		Each(func(id lib.EntityID, values map[lib.ComponentID]interface{}) {
+			position := values[components.PositionComponentID].(components.PositionComponent)
+			text := values[components.TextComponentID].(components.TextComponent)
+			r.backend.DrawText(text.Text, position.X, position.Y, text.FontSize, Black)
+		})
+}
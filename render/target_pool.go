@@ -0,0 +1,56 @@
+package render
+
+// TargetPool owns a fixed number of offscreen targets that all share the
+// window's render size. They are kept across frames and only recreated when
+// that size changes, which covers both window resizes and DPI changes.
+type TargetPool struct {
+	backend       Backend
+	targets       []Target
+	loaded        bool
+	width, height int32
+	allocations   int
+}
+
+func NewTargetPool(backend Backend, count int) *TargetPool {
+	return &TargetPool{backend: backend, targets: make([]Target, count)}
+}
+
+// Frame returns the pool's targets at the given size, recreating them if the
+// size differs from the loaded ones. An empty size, as with a minimised
+// window, returns no targets and keeps the loaded ones for when it comes back.
+func (p *TargetPool) Frame(width, height int32) []Target {
+	p.allocations = 0
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	if p.loaded && width == p.width && height == p.height {
+		return p.targets
+	}
+	p.unload()
+	for i := range p.targets {
+		p.targets[i] = p.backend.LoadTarget(width, height)
+		p.allocations++
+	}
+	p.loaded = true
+	p.width, p.height = width, height
+	return p.targets
+}
+
+// Allocations reports how many targets the last Frame call created.
+func (p *TargetPool) Allocations() int {
+	return p.allocations
+}
+
+func (p *TargetPool) Close() {
+	p.unload()
+}
+
+func (p *TargetPool) unload() {
+	if !p.loaded {
+		return
+	}
+	for _, target := range p.targets {
+		p.backend.UnloadTarget(target)
+	}
+	p.loaded = false
+}
@@ -0,0 +1,108 @@
+package render
+
+import (
+	"testing"
+
+	"ecs/systems"
+)
+
+func countCalls(backend *RecordingBackend, name string) int {
+	count := 0
+	for _, call := range backend.Calls() {
+		if call.Name == name {
+			count++
+		}
+	}
+	return count
+}
+
+func TestTargetPool(t *testing.T) {
+	backend := NewRecordingBackend()
+	pool := NewTargetPool(backend, 2)
+
+	frames := []struct {
+		width, height int32
+		allocations   int
+	}{
+		{0, 0, 0},
+		{800, 450, 2},
+		{800, 450, 0},
+		{0, 0, 0},
+		{800, 0, 0},
+		{800, 450, 0},
+		{1600, 900, 2},
+		{1600, 900, 0},
+	}
+	for i, frame := range frames {
+		targets := pool.Frame(frame.width, frame.height)
+		if pool.Allocations() != frame.allocations {
+			t.Errorf("frame %d: expected %d allocations, got %d", i, frame.allocations, pool.Allocations())
+		}
+		if empty := frame.width == 0 || frame.height == 0; empty != (targets == nil) {
+			t.Errorf("frame %d: expected targets only for a non-empty size, got %v", i, targets)
+		}
+		for _, target := range targets {
+			if target.Width != frame.width || target.Height != frame.height {
+				t.Errorf("frame %d: expected %dx%d target, got %+v", i, frame.width, frame.height, target)
+			}
+		}
+	}
+	if loads, unloads := countCalls(backend, "LoadTarget"), countCalls(backend, "UnloadTarget"); loads != 4 || unloads != 2 {
+		t.Errorf("expected 4 loads and 2 unloads before closing, got %d and %d", loads, unloads)
+	}
+
+	pool.Close()
+	pool.Close()
+	if unloads := countCalls(backend, "UnloadTarget"); unloads != 4 {
+		t.Errorf("expected every target to be unloaded once, got %d unloads", unloads)
+	}
+}
+
+func TestPipeline_ReusesTargets(t *testing.T) {
+	backend := NewRecordingBackend()
+	window := &resizableWindow{width: 800, height: 450}
+	pipeline, err := NewPipeline(backend, window, systems.FixedClock{Step: 0.5}, fakeInput{}, newDemoChain(t, backend))
+	if err != nil {
+		t.Fatal(err)
+	}
+	world := newRenderWorld()
+
+	for frame := 0; frame < 5; frame++ {
+		if frame == 3 {
+			window.width, window.height = 1600, 900
+		}
+		pipeline.Render(world, 0)
+		expected := 0
+		if frame == 0 || frame == 3 {
+			expected = 2
+		}
+		if got := pipeline.Targets().Allocations(); got != expected {
+			t.Errorf("frame %d: expected %d allocations, got %d", frame, expected, got)
+		}
+	}
+}
+
+func TestPipeline_SkipsEmptyFrame(t *testing.T) {
+	backend := NewRecordingBackend()
+	pipeline, err := NewPipeline(backend, &resizableWindow{}, systems.FixedClock{Step: 0.5}, fakeInput{}, newDemoChain(t, backend))
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.Reset()
+	pipeline.Render(newRenderWorld(), 0)
+	if got := backend.String(); got != "BeginFrame\nEndFrame\n" {
+		t.Errorf("expected an empty frame for a minimised window, got:\n%s", got)
+	}
+}
+
+type resizableWindow struct {
+	width, height int32
+}
+
+func (w *resizableWindow) RenderSize() (int32, int32) {
+	return w.width, w.height
+}
+
+func (w *resizableWindow) ScreenSize() (int32, int32) {
+	return w.width / 2, w.height / 2
+}
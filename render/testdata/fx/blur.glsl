@@ -0,0 +1,14 @@
+#version 330
+
+in vec2 fragTexCoord;
+in vec4 fragColor;
+
+uniform sampler2D texture0;
+uniform vec4 colDiffuse;
+
+out vec4 finalColor;
+
+// Pass-through stand-in for chain tests; only its path is checked.
+void main() {
+    finalColor = texture(texture0, fragTexCoord) * colDiffuse * fragColor;
+}
@@ -0,0 +1,47 @@
+package systems
+
+import (
+	"ecs/components"
+	"ecs/lib"
+)
+
+type AttractionConfig struct {
+	// MaxRadius is a particle's radius in logical pixels when it is a full
+	// screen width away from the mouse; it shrinks as the particle closes in.
+	MaxRadius int32
+}
+
+var DefaultAttractionConfig = AttractionConfig{MaxRadius: 10}
+
+// AttractionSystem pulls particles towards the mouse at their own speed.
+type AttractionSystem struct {
+	config AttractionConfig
+	input  Input
+	screen Screen
+}
+
+func NewAttractionSystem(config AttractionConfig, input Input, screen Screen) *AttractionSystem {
+	return &AttractionSystem{config: config, input: input, screen: screen}
+}
+
+func (s *AttractionSystem) Update(world *lib.World, deltaTime float64) {
+	mouseX, mouseY := mousePosition(s.input)
+	renderWidth, _ := s.screen.RenderSize()
+	maxRadius := float32(ToScaled(s.screen, s.config.MaxRadius))
+
+	world.Query().
+		With(components.ParticleComponentID, components.PositionComponentID, components.SpeedComponentID).
+		Each(func(id lib.EntityID, values map[lib.ComponentID]interface{}) {
+			position := values[components.PositionComponentID].(components.PositionComponent)
+			speed := values[components.SpeedComponentID].(components.SpeedComponent)
+			amount := speed.Speed * float32(deltaTime)
+			movedX := lerp(float32(position.X), mouseX, amount)
+			movedY := lerp(float32(position.Y), mouseY, amount)
+			position.X = int32(movedX)
+			position.Y = int32(movedY)
+			world.AddComponents(id,
+				position,
+				components.ParticleComponent{Radius: maxRadius * distance(mouseX, mouseY, movedX, movedY) / float32(renderWidth)},
+			)
+		})
+}
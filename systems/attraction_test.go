@@ -0,0 +1,31 @@
+package systems
+
+import (
+	"testing"
+
+	"ecs/components"
+	"ecs/lib"
+	"ecs/lib/replay"
+)
+
+func TestAttractionSystem(t *testing.T) {
+	clock := &fakeClock{}
+	input := &fakeInput{input: replay.Input{MouseX: 200, MouseY: 0}}
+	screen := FixedScreen{Width: 400, Height: 300, DPI: 2}
+	world := newSystemWorld(NewAttractionSystem(DefaultAttractionConfig, input, screen))
+	particle := world.CreateEntity()
+	world.AddComponents(particle,
+		components.ParticleComponent{},
+		components.PositionComponent{X: 0, Y: 0},
+		components.SpeedComponent{Speed: 2},
+	)
+
+	clock.Advance(world, 0.25)
+	position, _ := lib.Get[components.PositionComponent](world, particle)
+	if position != (components.PositionComponent{X: 100, Y: 0}) {
+		t.Errorf("expected particle to cover half the distance, got %+v", position)
+	}
+	if got, _ := lib.Get[components.ParticleComponent](world, particle); got.Radius != 5 {
+		t.Errorf("expected radius 20 * 100/400 = 5, got %v", got.Radius)
+	}
+}
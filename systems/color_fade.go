@@ -0,0 +1,38 @@
+package systems
+
+import (
+	"ecs/components"
+	"ecs/lib"
+)
+
+type ColorFadeConfig struct {
+	// FadeOutAt is the fraction of its lifetime by which a particle has
+	// become fully transparent.
+	FadeOutAt float32
+}
+
+var DefaultColorFadeConfig = ColorFadeConfig{FadeOutAt: 0.7}
+
+// ColorFadeSystem shifts particles from red towards yellow and fades them out
+// as their lifetime runs down.
+type ColorFadeSystem struct {
+	config ColorFadeConfig
+}
+
+func NewColorFadeSystem(config ColorFadeConfig) *ColorFadeSystem {
+	return &ColorFadeSystem{config: config}
+}
+
+func (s *ColorFadeSystem) Update(world *lib.World, _ float64) {
+	world.Query().
+		With(components.LifetimeComponentID, components.ParticleComponentID, components.ColorComponentID).
+		Each(func(id lib.EntityID, values map[lib.ComponentID]interface{}) {
+			lifetime := values[components.LifetimeComponentID].(components.LifetimeComponent)
+			color := values[components.ColorComponentID].(components.ColorComponent)
+			age := lifetime.CurrentTime / lifetime.LifeTime
+			color.G = uint8(255.0 * age)
+			color.B = uint8(1 - age)
+			color.A = uint8(max(255.0*(s.config.FadeOutAt-age), 0.0))
+			world.AddComponents(id, color)
+		})
+}
@@ -0,0 +1,33 @@
+package systems
+
+import (
+	"testing"
+
+	"ecs/components"
+	"ecs/lib"
+)
+
+func TestColorFadeSystem(t *testing.T) {
+	tests := []struct {
+		age      float32
+		expected components.ColorComponent
+	}{
+		{0, components.ColorComponent{R: 255, G: 0, B: 1, A: 178}},
+		{0.5, components.ColorComponent{R: 255, G: 127, B: 0, A: 50}},
+		{0.8, components.ColorComponent{R: 255, G: 204, B: 0, A: 0}},
+	}
+	for _, tt := range tests {
+		world := newSystemWorld(NewColorFadeSystem(DefaultColorFadeConfig))
+		particle := world.CreateEntity()
+		world.AddComponents(particle,
+			components.ParticleComponent{},
+			components.ColorComponent{R: 255, A: 255},
+			components.LifetimeComponent{LifeTime: 10, CurrentTime: tt.age * 10},
+		)
+		(&fakeClock{}).Advance(world, 0.1)
+
+		if color, _ := lib.Get[components.ColorComponent](world, particle); color != tt.expected {
+			t.Errorf("age %v: expected %+v, got %+v", tt.age, tt.expected, color)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package systems
+
+import (
+	"ecs/components"
+	"ecs/lib"
+)
+
+// DebounceSystem enables ShouldUpdateComponent for a single tick each time an
+// entity's DebounceUpdateComponent timer runs out, so slow-changing text is
+// only refreshed a few times a second.
+type DebounceSystem struct{}
+
+func NewDebounceSystem() *DebounceSystem {
+	return &DebounceSystem{}
+}
+
+func (s *DebounceSystem) Update(world *lib.World, deltaTime float64) {
+	world.Query().With(components.ShouldUpdateComponentID).Each(func(id lib.EntityID, _ map[lib.ComponentID]interface{}) {
+		world.DisableComponent(id, components.ShouldUpdateComponentID)
+	})
+
+	world.Query().
+		With(components.DebounceUpdateComponentID).
+		Each(func(id lib.EntityID, values map[lib.ComponentID]interface{}) {
+			debounceUpdate := values[components.DebounceUpdateComponentID].(components.DebounceUpdateComponent)
+			debounceUpdate.CurrentTime += float32(deltaTime)
+			if debounceUpdate.CurrentTime >= debounceUpdate.DebounceTime {
+				debounceUpdate.CurrentTime = 0
+				world.EnableComponent(id, components.ShouldUpdateComponentID)
+			}
+			world.AddComponents(id, debounceUpdate)
+		})
+}
@@ -0,0 +1,33 @@
+package systems
+
+import (
+	"slices"
+	"testing"
+
+	"ecs/components"
+	"ecs/lib"
+)
+
+func TestDebounceSystem(t *testing.T) {
+	clock := &fakeClock{}
+	world := newSystemWorld(NewDebounceSystem())
+	label := world.CreateEntity()
+	world.AddComponents(label,
+		components.DebounceUpdateComponent{DebounceTime: 0.25},
+		components.ShouldUpdateComponent{},
+	)
+
+	var updated []int
+	for tick := 1; tick <= 10; tick++ {
+		clock.Advance(world, 0.125)
+		if world.HasComponent(label, components.ShouldUpdateComponentID) {
+			updated = append(updated, tick)
+		}
+	}
+	if expected := []int{2, 4, 6, 8, 10}; !slices.Equal(updated, expected) {
+		t.Errorf("expected updates on ticks %v, got %v", expected, updated)
+	}
+	if timer, _ := lib.Get[components.DebounceUpdateComponent](world, label); timer.CurrentTime != 0 {
+		t.Errorf("expected timer to reset, got %+v", timer)
+	}
+}
@@ -0,0 +1,42 @@
+package systems
+
+import (
+	"ecs/lib"
+	"ecs/lib/replay"
+)
+
+// fakeClock stands in for the frame timer: Advance runs one world update with
+// the given step and reports it as the last frame time.
+type fakeClock struct {
+	fps       int32
+	frameTime float32
+}
+
+func (c *fakeClock) FPS() int32 {
+	return c.fps
+}
+
+func (c *fakeClock) FrameTime() float32 {
+	return c.frameTime
+}
+
+func (c *fakeClock) Advance(world *lib.World, step float64) {
+	c.frameTime = float32(step)
+	world.Update(step)
+}
+
+type fakeInput struct {
+	input replay.Input
+}
+
+func (i *fakeInput) Current() replay.Input {
+	return i.input
+}
+
+func newSystemWorld(systems ...lib.System) *lib.World {
+	world := lib.NewWorld()
+	for _, system := range systems {
+		world.AddSystem(system)
+	}
+	return world
+}
@@ -0,0 +1,45 @@
+package systems
+
+import (
+	"fmt"
+
+	"ecs/components"
+	"ecs/lib"
+)
+
+// HUDSystem refreshes the FPS, frame time and entity counter labels. The
+// FPS and frame time labels only change on ticks where their debounce timer
+// has enabled ShouldUpdateComponent. The FPS label follows the mouse.
+type HUDSystem struct {
+	clock Clock
+	input Input
+}
+
+func NewHUDSystem(clock Clock, input Input) *HUDSystem {
+	return &HUDSystem{clock: clock, input: input}
+}
+
+func (s *HUDSystem) Update(world *lib.World, _ float64) {
+	mouseX, mouseY := mousePosition(s.input)
+	world.Query().
+		With(components.FPSComponentID, components.PositionComponentID).
+		Each(func(id lib.EntityID, _ map[lib.ComponentID]interface{}) {
+			world.AddComponents(id, components.PositionComponent{X: int32(mouseX), Y: int32(mouseY)})
+		})
+
+	s.setText(world, components.FPSComponentID, true, fmt.Sprintf("FPS: %d", s.clock.FPS()))
+	s.setText(world, components.FrameTimeComponentID, true, fmt.Sprintf("Frame time: %f", s.clock.FrameTime()))
+	s.setText(world, components.EntityCounterComponentID, false, fmt.Sprintf("Entities: %d", world.GetEntityCount()))
+}
+
+func (s *HUDSystem) setText(world *lib.World, labelID lib.ComponentID, debounced bool, text string) {
+	query := world.Query().With(labelID, components.TextComponentID)
+	if debounced {
+		query = query.With(components.ShouldUpdateComponentID)
+	}
+	query.Each(func(id lib.EntityID, values map[lib.ComponentID]interface{}) {
+		label := values[components.TextComponentID].(components.TextComponent)
+		label.Text = text
+		world.AddComponents(id, label)
+	})
+}
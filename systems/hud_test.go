@@ -0,0 +1,42 @@
+package systems
+
+import (
+	"testing"
+
+	"ecs/components"
+	"ecs/lib"
+	"ecs/lib/replay"
+)
+
+func TestHUDSystem(t *testing.T) {
+	clock := &fakeClock{fps: 60}
+	input := &fakeInput{input: replay.Input{MouseX: 30, MouseY: 40}}
+	world := newSystemWorld(NewDebounceSystem(), NewHUDSystem(clock, input))
+	fps := world.CreateEntity()
+	world.AddComponents(fps,
+		components.TextComponent{},
+		components.PositionComponent{},
+		components.FPSComponent{},
+		components.DebounceUpdateComponent{DebounceTime: 1},
+		components.ShouldUpdateComponent{},
+	)
+	counter := world.CreateEntity()
+	world.AddComponents(counter, components.TextComponent{}, components.EntityCounterComponent{})
+
+	clock.Advance(world, 0.5)
+	if text, _ := lib.Get[components.TextComponent](world, fps); text.Text != "" {
+		t.Errorf("expected FPS label to wait for its debounce, got %q", text.Text)
+	}
+	if text, _ := lib.Get[components.TextComponent](world, counter); text.Text != "Entities: 2" {
+		t.Errorf("expected entity counter to update every tick, got %q", text.Text)
+	}
+	if position, _ := lib.Get[components.PositionComponent](world, fps); position != (components.PositionComponent{X: 30, Y: 40}) {
+		t.Errorf("expected FPS label to follow the mouse, got %+v", position)
+	}
+
+	clock.fps = 30
+	clock.Advance(world, 0.5)
+	if text, _ := lib.Get[components.TextComponent](world, fps); text.Text != "FPS: 30" {
+		t.Errorf("expected FPS label to refresh, got %q", text.Text)
+	}
+}
@@ -0,0 +1,26 @@
+package systems
+
+import (
+	"ecs/components"
+	"ecs/lib"
+)
+
+// LifetimeSystem ages entities with a LifetimeComponent and destroys them
+// once their time is up.
+type LifetimeSystem struct{}
+
+func NewLifetimeSystem() *LifetimeSystem {
+	return &LifetimeSystem{}
+}
+
+func (s *LifetimeSystem) Update(world *lib.World, deltaTime float64) {
+	world.Query().With(components.LifetimeComponentID).Each(func(id lib.EntityID, values map[lib.ComponentID]interface{}) {
+		lifetime := values[components.LifetimeComponentID].(components.LifetimeComponent)
+		lifetime.CurrentTime += float32(deltaTime)
+		if lifetime.CurrentTime >= lifetime.LifeTime {
+			world.DestroyEntity(id)
+		} else {
+			world.AddComponents(id, lifetime)
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package systems
+
+import (
+	"testing"
+
+	"ecs/components"
+)
+
+func TestLifetimeSystem(t *testing.T) {
+	clock := &fakeClock{}
+	world := newSystemWorld(NewLifetimeSystem())
+	short, long := world.CreateEntity(), world.CreateEntity()
+	world.AddComponents(short, components.LifetimeComponent{LifeTime: 0.5})
+	world.AddComponents(long, components.LifetimeComponent{LifeTime: 1})
+
+	clock.Advance(world, 0.25)
+	if !world.HasEntity(short) || !world.HasEntity(long) {
+		t.Fatal("expected both entities to be alive")
+	}
+	clock.Advance(world, 0.25)
+	if world.HasEntity(short) || !world.HasEntity(long) {
+		t.Error("expected only the short-lived entity to expire")
+	}
+	clock.Advance(world, 0.5)
+	if world.HasEntity(long) {
+		t.Error("expected the long-lived entity to expire")
+	}
+}
@@ -0,0 +1,73 @@
+// Package systems implements the particle demo's behaviour as lib.System
+// types. Nothing here touches a window: input, frame timing and screen size
+// come in through the interfaces below.
+package systems
+
+import (
+	"math"
+
+	"ecs/lib/replay"
+)
+
+// Input is the player's input for the current tick. replay.InputSystem
+// implements it, so live, scripted and recorded input all look the same.
+type Input interface {
+	Current() replay.Input
+}
+
+// Clock reports how fast frames are actually rendered, for the HUD. The
+// simulation itself only uses the step it is updated with.
+type Clock interface {
+	FPS() int32
+	FrameTime() float32
+}
+
+type Screen interface {
+	RenderSize() (width, height int32)
+	ScaleDPI() float32
+}
+
+// FixedClock reports a steady frame rate, for running without a window.
+type FixedClock struct {
+	Step float64
+}
+
+func (c FixedClock) FPS() int32 {
+	return int32(1 / c.Step)
+}
+
+func (c FixedClock) FrameTime() float32 {
+	return float32(c.Step)
+}
+
+// FixedScreen is a screen that never resizes.
+type FixedScreen struct {
+	Width, Height int32
+	DPI           float32
+}
+
+func (s FixedScreen) RenderSize() (int32, int32) {
+	return s.Width, s.Height
+}
+
+func (s FixedScreen) ScaleDPI() float32 {
+	return s.DPI
+}
+
+// ToScaled converts a size in logical pixels to render pixels.
+func ToScaled(screen Screen, value int32) int32 {
+	return int32(math.Floor(float64(value) * float64(screen.ScaleDPI())))
+}
+
+func mousePosition(input Input) (float32, float32) {
+	current := input.Current()
+	return float32(current.MouseX), float32(current.MouseY)
+}
+
+func distance(x1, y1, x2, y2 float32) float32 {
+	return float32(math.Hypot(float64(x2-x1), float64(y2-y1)))
+}
+
+func lerp(from, to, amount float32) float32 {
+	return from + (to-from)*amount
+}
@@ -0,0 +1,60 @@
+package systems
+
+import (
+	"math/rand"
+
+	"ecs/components"
+	"ecs/lib"
+	"ecs/lib/replay"
+)
+
+type SpawnerConfig struct {
+	// Attempts is how many particles each click tries to place; those landing
+	// within ExclusionRadius of the mouse are skipped.
+	Attempts        int
+	ExclusionRadius float32
+	MaxSpeed        float32
+}
+
+var DefaultSpawnerConfig = SpawnerConfig{Attempts: 300, ExclusionRadius: 200, MaxSpeed: 3}
+
+// SpawnerSystem scatters particles around the screen whenever the left mouse
+// button is clicked, one batch per ParticleSpawnComponent. Randomness comes
+// from rng only, so a seeded spawner replays identically.
+type SpawnerSystem struct {
+	config SpawnerConfig
+	input  Input
+	screen Screen
+	rng    *rand.Rand
+}
+
+func NewSpawnerSystem(config SpawnerConfig, input Input, screen Screen, rng *rand.Rand) *SpawnerSystem {
+	return &SpawnerSystem{config: config, input: input, screen: screen, rng: rng}
+}
+
+func (s *SpawnerSystem) Update(world *lib.World, _ float64) {
+	if s.input.Current().Pressed&replay.ButtonLeft == 0 {
+		return
+	}
+	mouseX, mouseY := mousePosition(s.input)
+	renderWidth, renderHeight := s.screen.RenderSize()
+
+	world.Query().With(components.ParticleSpawnComponentID).Each(func(id lib.EntityID, values map[lib.ComponentID]interface{}) {
+		spawner := values[components.ParticleSpawnComponentID].(components.ParticleSpawnComponent)
+		for i := 0; i < s.config.Attempts; i++ {
+			x := int32(s.rng.Float32()*float32(renderWidth)*2 - float32(renderWidth)*0.5)
+			y := int32(s.rng.Float32()*float32(renderHeight)*1.5 - float32(renderHeight)*0.5)
+			if distance(mouseX, mouseY, float32(x), float32(y)) < s.config.ExclusionRadius {
+				continue
+			}
+			world.AddComponents(world.CreateEntity(),
+				components.ParticleComponent{},
+				components.PositionComponent{X: x, Y: y},
+				components.ColorComponent{R: 255, G: 0, B: 0, A: 255},
+				components.LifetimeComponent{LifeTime: spawner.LifeTime, CurrentTime: 0},
+				components.VisibleComponent{},
+				components.SpeedComponent{Speed: s.rng.Float32() * s.config.MaxSpeed},
+			)
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package systems
+
+import (
+	"math/rand"
+	"testing"
+
+	"ecs/components"
+	"ecs/lib"
+	"ecs/lib/replay"
+)
+
+func TestSpawnerSystem(t *testing.T) {
+	clock := &fakeClock{}
+	input := &fakeInput{input: replay.Input{MouseX: 100, MouseY: 100}}
+	config := SpawnerConfig{Attempts: 50, ExclusionRadius: 80, MaxSpeed: 2}
+	screen := FixedScreen{Width: 400, Height: 300, DPI: 1}
+	world := newSystemWorld(NewSpawnerSystem(config, input, screen, rand.New(rand.NewSource(1))))
+	world.AddComponents(world.CreateEntity(), components.ParticleSpawnComponent{LifeTime: 5})
+
+	clock.Advance(world, 0.1)
+	if got := world.GetEntityCount(); got != 1 {
+		t.Fatalf("expected nothing to spawn without a click, got %d entities", got)
+	}
+
+	input.input.Pressed = replay.ButtonLeft
+	clock.Advance(world, 0.1)
+	spawned := 0
+	for entity, position := range lib.Values[components.PositionComponent](world.Query().With(components.ParticleComponentID)) {
+		spawned++
+		if distance(100, 100, float32(position.X), float32(position.Y)) < config.ExclusionRadius {
+			t.Errorf("particle %d spawned inside the exclusion radius at %+v", entity, position)
+		}
+		speed, _ := lib.Get[components.SpeedComponent](world, entity)
+		lifetime, _ := lib.Get[components.LifetimeComponent](world, entity)
+		if speed.Speed < 0 || speed.Speed >= config.MaxSpeed || lifetime.LifeTime != 5 {
+			t.Errorf("particle %d has speed %v and lifetime %v", entity, speed.Speed, lifetime.LifeTime)
+		}
+	}
+	if spawned == 0 || spawned > config.Attempts {
+		t.Errorf("expected between 1 and %d particles, got %d", config.Attempts, spawned)
+	}
+}